@@ -0,0 +1,164 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Adjust mode. The adjust hotkey registers the arrow keys as temporary
+// hotkeys (ids from adjustHotKeyIDBase, below the ids used for the
+// configured bindings): arrows move the target window, Shift+arrows resize
+// it, Enter keeps the result and Escape puts the window back. Unlike the
+// leader keys they repeat while held.
+const adjustHotKeyIDBase = 150
+
+const adjustHint = "Adjust window\n" +
+	"Arrows\tMove\n" +
+	"Shift + Arrows\tResize\n" +
+	"Enter\tDone\n" +
+	"Esc\tCancel"
+
+var (
+	adjustConfig = AdjustConfig{Step: DEFAULT_ADJUST_STEP, ResizeStep: DEFAULT_ADJUST_RESIZE_STEP}
+	adjustTarget w32.HWND
+	// window placement to go back to on Escape
+	adjustOriginal  w32.RECT
+	adjustMaximized bool
+	adjustHotKeys   []HotKey
+)
+
+// adjustStep returns the change an arrow key makes in adjust mode: arrows
+// move by step, Shift+arrows move the right or bottom edge by resizeStep.
+func adjustStep(mod, vk int, step, resizeStep int32) (resizeFunc, bool) {
+	var dx, dy int32
+	switch vk {
+	case w32.VK_LEFT:
+		dx = -1
+	case w32.VK_RIGHT:
+		dx = 1
+	case w32.VK_UP:
+		dy = -1
+	case w32.VK_DOWN:
+		dy = 1
+	default:
+		return nil, false
+	}
+	switch mod {
+	case 0:
+		return moveBy(dx*step, dy*step), true
+	case MOD_SHIFT:
+		return growBy(dx*resizeStep, dy*resizeStep), true
+	}
+	return nil, false
+}
+
+func enterAdjustMode() {
+	if adjustTarget != 0 {
+		return
+	}
+	hwnd := getTargetWindow()
+	if !isZonableWindow(hwnd) {
+		fmt.Println("warn: adjust: no zonable window")
+		return
+	}
+	rect := w32.GetWindowRect(hwnd)
+	if rect == nil {
+		return
+	}
+	adjustTarget = hwnd
+	adjustOriginal = *rect
+	adjustMaximized = w32ex.IsZoomed(hwnd)
+
+	type adjustKey struct{ mod, vk int }
+	var keys []adjustKey
+	for _, mod := range []int{0, MOD_SHIFT} {
+		for _, vk := range []int{w32.VK_LEFT, w32.VK_RIGHT, w32.VK_UP, w32.VK_DOWN} {
+			keys = append(keys, adjustKey{mod, vk})
+		}
+	}
+	keys = append(keys, adjustKey{0, w32.VK_RETURN}, adjustKey{0, w32.VK_ESCAPE})
+	for i, k := range keys {
+		k := k
+		hk := HotKey{
+			id:          adjustHotKeyIDBase + i,
+			mod:         k.mod,
+			vk:          k.vk,
+			callback:    func() { adjustPress(k.mod, k.vk) },
+			bindFeature: "adjust",
+		}
+		if RegisterHotKey(hk) {
+			adjustHotKeys = append(adjustHotKeys, hk)
+		} else {
+			fmt.Printf("warn: adjust key in use by another process: %s\n", hk.Describe())
+		}
+	}
+	showOverlay(adjustHint)
+}
+
+func adjustPress(mod, vk int) {
+	switch vk {
+	case w32.VK_RETURN:
+		exitAdjustMode()
+		return
+	case w32.VK_ESCAPE:
+		cancelAdjustMode()
+		return
+	}
+	if !w32.IsWindow(adjustTarget) {
+		exitAdjustMode()
+		return
+	}
+	f, ok := adjustStep(mod, vk, adjustConfig.Step, adjustConfig.ResizeStep)
+	if !ok {
+		return
+	}
+	resizeFn := resize
+	if mod == MOD_SHIFT {
+		resizeFn = resizeLinked
+	}
+	if _, err := resizeFn(adjustTarget, f); err != nil {
+		fmt.Printf("warn: resize: %v\n", err)
+	}
+}
+
+// cancelAdjustMode puts the window back where it was when adjust mode
+// started.
+func cancelAdjustMode() {
+	hwnd, r := adjustTarget, adjustOriginal
+	exitAdjustMode()
+	if !w32.IsWindow(hwnd) {
+		return
+	}
+	if adjustMaximized {
+		w32.ShowWindow(hwnd, w32.SW_MAXIMIZE)
+		return
+	}
+	if !w32.SetWindowPos(hwnd, 0, int(r.Left), int(r.Top), int(r.Width()), int(r.Height()), w32.SWP_NOZORDER|w32.SWP_NOACTIVATE) {
+		fmt.Printf("warn: failed to SetWindowPos:%d\n", w32.GetLastError())
+	}
+}
+
+func exitAdjustMode() {
+	adjustTarget = 0
+	for _, hk := range adjustHotKeys {
+		UnregisterHotKey(hk)
+	}
+	adjustHotKeys = nil
+	hideOverlay()
+}
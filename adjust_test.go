@@ -0,0 +1,69 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestAdjustStep(t *testing.T) {
+	disp := rect(0, 0, 1000, 1000)
+	cur := rect(100, 100, 300, 300)
+	cases := []struct {
+		name    string
+		mod, vk int
+		want    w32.RECT
+	}{
+		{"left", 0, w32.VK_LEFT, rect(90, 100, 290, 300)},
+		{"right", 0, w32.VK_RIGHT, rect(110, 100, 310, 300)},
+		{"up", 0, w32.VK_UP, rect(100, 90, 300, 290)},
+		{"down", 0, w32.VK_DOWN, rect(100, 110, 300, 310)},
+		{"shift+right widens", MOD_SHIFT, w32.VK_RIGHT, rect(100, 100, 325, 300)},
+		{"shift+left narrows", MOD_SHIFT, w32.VK_LEFT, rect(100, 100, 275, 300)},
+		{"shift+down lengthens", MOD_SHIFT, w32.VK_DOWN, rect(100, 100, 300, 325)},
+		{"shift+up shortens", MOD_SHIFT, w32.VK_UP, rect(100, 100, 300, 275)},
+	}
+	for _, c := range cases {
+		f, ok := adjustStep(c.mod, c.vk, 10, 25)
+		if !ok {
+			t.Errorf("%s: adjustStep returned no step", c.name)
+			continue
+		}
+		if got := f(disp, cur); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+	for _, k := range []struct{ mod, vk int }{{0, w32.VK_RETURN}, {MOD_CONTROL, w32.VK_LEFT}, {0, 'A'}} {
+		if _, ok := adjustStep(k.mod, k.vk, 10, 25); ok {
+			t.Errorf("adjustStep(0x%x, 0x%x) should not return a step", k.mod, k.vk)
+		}
+	}
+}
+
+func TestAdjustStepRepeats(t *testing.T) {
+	// steps are repeatable and stop at the display edge
+	disp := rect(0, 0, 1000, 1000)
+	r := rect(100, 100, 300, 300)
+	f, _ := adjustStep(0, w32.VK_LEFT, 30, 0)
+	for i := 0; i < 5; i++ {
+		r = f(disp, r)
+	}
+	if want := rect(0, 100, 200, 300); !reflect.DeepEqual(r, want) {
+		t.Errorf("after 5 steps left got %+v, want %+v", r, want)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gonutz/w32/v2"
+)
+
+// One-shot arrangements of all windows on a monitor (tileAll, cascadeAll,
+// ...). The placements from before each arrangement are kept so that undo
+// can put the windows back.
+
+// maxUndo is how many arrangements undo can go back.
+const maxUndo = 10
+
+// cascadeStep is the offset between cascaded windows, about the height of
+// a title bar.
+const cascadeStep = 40
+
+type windowPlacement struct {
+	hwnd      w32.HWND
+	placement w32.WINDOWPLACEMENT
+}
+
+var (
+	undoMu sync.Mutex
+	// keyed by desktopScope
+	undoStacks = map[desktopID][][]windowPlacement{}
+	// set from VirtualDesktopConfig.PerDesktopUndo
+	perDesktopUndo bool
+)
+
+func saveUndo(windows []w32.HWND) {
+	var saved []windowPlacement
+	for _, hwnd := range windows {
+		p := windowPlacement{hwnd: hwnd}
+		if w32.GetWindowPlacement(hwnd, &p.placement) {
+			saved = append(saved, p)
+		}
+	}
+	scope := desktopScope(desktops, perDesktopUndo)
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	stack := append(undoStacks[scope], saved)
+	if len(stack) > maxUndo {
+		stack = stack[1:]
+	}
+	undoStacks[scope] = stack
+}
+
+// undoArrange restores the windows moved by the last arrangement.
+func undoArrange() {
+	scope := desktopScope(desktops, perDesktopUndo)
+	undoMu.Lock()
+	stack := undoStacks[scope]
+	if len(stack) == 0 {
+		undoMu.Unlock()
+		fmt.Println("nothing to undo")
+		return
+	}
+	saved := stack[len(stack)-1]
+	undoStacks[scope] = stack[:len(stack)-1]
+	undoMu.Unlock()
+	for _, p := range saved {
+		if !w32.IsWindow(p.hwnd) {
+			continue
+		}
+		placement := p.placement
+		if placement.ShowCmd != w32.SW_SHOWMAXIMIZED {
+			placement.ShowCmd = w32.SW_SHOWNOACTIVATE
+		}
+		if !w32.SetWindowPlacement(p.hwnd, &placement) {
+			fmt.Printf("warn: failed to SetWindowPlacement:%d\n", w32.GetLastError())
+		}
+	}
+}
+
+// arrangeAll places every visible zonable window on the monitor of the
+// target window (or under the cursor) by layout, topmost window first.
+func arrangeAll(layout func(work w32.RECT, n int) []w32.RECT) {
+	mon := targetMonitor()
+	var windows []w32.HWND
+	for _, hwnd := range zonableWindows() {
+		if w32.MonitorFromWindow(hwnd, w32.MONITOR_DEFAULTTONEAREST) == mon {
+			windows = append(windows, hwnd)
+		}
+	}
+	if len(windows) == 0 {
+		return
+	}
+	saveUndo(windows)
+	n := len(windows)
+	// bottom first, so that resizeOnMonitor activating each window in turn
+	// leaves the z-order as it was
+	for i := n - 1; i >= 0; i-- {
+		i := i
+		f := func(disp, _ w32.RECT) w32.RECT { return layout(disp, n)[i] }
+		if _, err := resizeOnMonitor(windows[i], f, mon); err != nil {
+			fmt.Printf("warn: arrange: %v\n", err)
+		}
+	}
+}
+
+func tileAll()           { arrangeAll(gridLayout) }
+func tileAllVertical()   { arrangeAll(columnsLayout) }
+func tileAllHorizontal() { arrangeAll(rowsLayout) }
+
+// cascadeAll puts the topmost window at the front of the cascade, i.e. at
+// its bottom-right end.
+func cascadeAll() {
+	arrangeAll(func(work w32.RECT, n int) []w32.RECT {
+		rects := cascadeLayout(work, n, cascadeStep)
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			rects[i], rects[j] = rects[j], rects[i]
+		}
+		return rects
+	})
+}
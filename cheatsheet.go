@@ -0,0 +1,192 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/gonutz/w32/v2"
+)
+
+// The hotkey cheat sheet lists the configured hotkeys grouped by category.
+// The cheatSheet feature shows it in the overlay while its hotkey is held,
+// see cheatsheet_win.go, and --print-keymap prints it as Markdown or HTML.
+
+// posted to the msgLoop thread to show the cheat sheet
+const msgShowCheatSheet = w32.WM_APP + 2
+
+type keymapCategory struct {
+	name string
+	// the features of the category, in the order they are listed
+	features []string
+}
+
+// keymapCategories lists the categories in order. A feature belongs to the
+// first one listing it, and features listed nowhere to "Other".
+var keymapCategories = []keymapCategory{
+	{"Halves", []string{"moveToLeft", "moveToRight", "moveToTop", "moveToBottom", "centerHalf",
+		"pushToLeft", "pushToRight", "pushToTop", "pushToBottom"}},
+	{"Corners", []string{"moveToTopLeft", "moveToTopRight", "moveToBottomLeft", "moveToBottomRight"}},
+	{"Thirds and More", fractionLayoutNames()},
+	{"Displays", []string{"nextDisplay", "prevDisplay", "moveToDisplay",
+		"moveToNextDesktop", "moveToPrevDesktop", "moveToDesktop"}},
+	{"Sizing", []string{"maximize", "almostMaximize", "makeFullHeight", "makeLarger", "makeSmaller",
+		"moveToCenter", "moveBy", "resizeBy", "adjust",
+		"growLeft", "growRight", "growTop", "growBottom",
+		"shrinkLeft", "shrinkRight", "shrinkTop", "shrinkBottom"}},
+	{"Arranging", []string{"tileAll", "tileAllVertical", "tileAllHorizontal", "cascadeAll", "undo",
+		"swapWithNext", "swapLeft", "swapRight", "swapUp", "swapDown",
+		"focusLeft", "focusRight", "focusUp", "focusDown",
+		"toggleTiling", "cycleTilingLayout", "promoteToMaster", "swapTileNext", "swapTilePrev",
+		"growMaster", "shrinkMaster"}},
+	{"Window State", []string{"toggleAlwaysOnTop", "pictureInPicture",
+		"toggleTransparency", "opacityUp", "opacityDown", "toggleClickThrough",
+		"minimize", "minimizeOthers", "restoreAllMinimized", "hideToTray"}},
+	{"Other", nil},
+}
+
+func fractionLayoutNames() []string {
+	var names []string
+	for _, l := range fractionLayouts {
+		names = append(names, l.name)
+	}
+	return names
+}
+
+// keymapPosition is the index of the category of feature and its index
+// within the category. Size presets are listed after the other sizing
+// features.
+func keymapPosition(feature string) (category, index int) {
+	for c, cat := range keymapCategories {
+		for i, f := range cat.features {
+			if f == feature {
+				return c, i
+			}
+		}
+		if cat.name == "Sizing" && strings.HasPrefix(feature, sizePresetPrefix) {
+			return c, len(cat.features)
+		}
+	}
+	return len(keymapCategories) - 1, 0
+}
+
+type keymapEntry struct {
+	Keys string
+	Name string
+}
+
+type keymapGroup struct {
+	Name    string
+	Entries []keymapEntry
+}
+
+// keymapName is the name the cheat sheet shows for the feature bound by hk.
+func keymapName(hk HotKey) string {
+	name, ok := featureDisplayNames[hk.bindFeature]
+	if !ok {
+		if reg, ok := featureRegistry[hk.bindFeature]; ok {
+			name = reg.DisplayName
+		} else {
+			name = hk.bindFeature
+		}
+	}
+	if hk.args != "" {
+		name += " (" + hk.args + ")"
+	}
+	return name
+}
+
+// keymapGroups groups the hotkeys hks by category, leaving out empty
+// categories.
+func keymapGroups(hks []HotKey) []keymapGroup {
+	type position struct{ category, index, order int }
+	positions := make([]position, len(hks))
+	order := make([]int, len(hks))
+	for i, hk := range hks {
+		c, idx := keymapPosition(hk.bindFeature)
+		positions[i] = position{c, idx, i}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := positions[order[a]], positions[order[b]]
+		if pa.category != pb.category {
+			return pa.category < pb.category
+		}
+		return pa.index < pb.index
+	})
+	var groups []keymapGroup
+	last := -1
+	for _, i := range order {
+		if c := positions[i].category; c != last {
+			groups = append(groups, keymapGroup{Name: keymapCategories[c].name})
+			last = c
+		}
+		g := &groups[len(groups)-1]
+		g.Entries = append(g.Entries, keymapEntry{Keys: hks[i].ShortDescribe(), Name: keymapName(hks[i])})
+	}
+	return groups
+}
+
+// keymapColumns splits groups into columns of at most maxRows rows, each
+// group taking a row for its name, one per entry and a blank one after
+// it. Groups are not split, so a group longer than maxRows gets a column
+// of its own.
+func keymapColumns(groups []keymapGroup, maxRows int) [][]keymapGroup {
+	var columns [][]keymapGroup
+	rows := 0
+	for _, g := range groups {
+		n := len(g.Entries) + 1
+		if len(columns) == 0 || rows+1+n > maxRows {
+			columns = append(columns, nil)
+			rows = -1 // no blank row before the first group
+		}
+		columns[len(columns)-1] = append(columns[len(columns)-1], g)
+		rows += 1 + n
+	}
+	return columns
+}
+
+// formatKeymap renders groups for --print-keymap in format, "markdown" or
+// "html".
+func formatKeymap(groups []keymapGroup, format string) (string, error) {
+	var b strings.Builder
+	switch strings.ToLower(format) {
+	case "markdown", "md":
+		b.WriteString("# RectangleWin Plus Hotkeys\n")
+		cell := func(s string) string { return strings.ReplaceAll(s, "|", `\|`) }
+		for _, g := range groups {
+			fmt.Fprintf(&b, "\n## %s\n\n| Hotkey | Action |\n| --- | --- |\n", g.Name)
+			for _, e := range g.Entries {
+				fmt.Fprintf(&b, "| `%s` | %s |\n", cell(e.Keys), cell(e.Name))
+			}
+		}
+	case "html":
+		b.WriteString("<h1>RectangleWin Plus Hotkeys</h1>\n")
+		for _, g := range groups {
+			fmt.Fprintf(&b, "<h2>%s</h2>\n<table>\n<tr><th>Hotkey</th><th>Action</th></tr>\n", html.EscapeString(g.Name))
+			for _, e := range g.Entries {
+				fmt.Fprintf(&b, "<tr><td><kbd>%s</kbd></td><td>%s</td></tr>\n",
+					html.EscapeString(e.Keys), html.EscapeString(e.Name))
+			}
+			b.WriteString("</table>\n")
+		}
+	default:
+		return "", fmt.Errorf("unknown keymap format %q, want markdown or html", format)
+	}
+	return b.String(), nil
+}
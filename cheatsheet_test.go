@@ -0,0 +1,121 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeymapGroups(t *testing.T) {
+	// size presets are only in the registry
+	featureRegistry = map[string]featureRegistration{
+		sizePresetPrefix + "1080p": simpleFeature(sizePresetDisplayName("1080p"), func() {}),
+	}
+	defer func() { featureRegistry = nil }()
+	hks := []HotKey{
+		{mod: MOD_CONTROL | MOD_ALT, vk: 'W', bindFeature: "windowPicker"},
+		{mod: MOD_CONTROL | MOD_ALT, vk: 0x0D, bindFeature: "maximize"},
+		{mod: MOD_CONTROL | MOD_ALT, vk: 0x27, bindFeature: "moveToRight"},
+		{mod: MOD_CONTROL | MOD_ALT, vk: 0x25, bindFeature: "moveToLeft"},
+		{mod: MOD_CONTROL | MOD_ALT, vk: 'E', bindFeature: "lastTwoThirds"},
+		{mod: MOD_CONTROL | MOD_ALT, vk: '2', bindFeature: "moveToDisplay", hasArgs: true, args: "2"},
+		{mod: MOD_CONTROL | MOD_ALT, vk: '1', bindFeature: sizePresetPrefix + "1080p"},
+		{mod: MOD_CONTROL | MOD_ALT, vk: 'Z', bindFeature: "somethingNew"},
+	}
+	want := []keymapGroup{
+		{"Halves", []keymapEntry{
+			{"Ctrl + Alt + LEFT ARROW", "Left half"},
+			{"Ctrl + Alt + RIGHT ARROW", "Right half"},
+		}},
+		{"Thirds and More", []keymapEntry{{"Ctrl + Alt + E", featureDisplayNames["lastTwoThirds"]}}},
+		{"Displays", []keymapEntry{{"Ctrl + Alt + 2", "Move to Display (2)"}}},
+		{"Sizing", []keymapEntry{
+			{"Ctrl + Alt + ENTER", "Maximize"},
+			{"Ctrl + Alt + 1", "Size: 1080p"},
+		}},
+		{"Other", []keymapEntry{
+			{"Ctrl + Alt + W", "Window Picker"},
+			{"Ctrl + Alt + Z", "somethingNew"},
+		}},
+	}
+	if got := keymapGroups(hks); !reflect.DeepEqual(got, want) {
+		t.Errorf("keymapGroups =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestKeymapColumns(t *testing.T) {
+	group := func(name string, n int) keymapGroup {
+		return keymapGroup{Name: name, Entries: make([]keymapEntry, n)}
+	}
+	names := func(columns [][]keymapGroup) [][]string {
+		var out [][]string
+		for _, col := range columns {
+			var c []string
+			for _, g := range col {
+				c = append(c, g.Name)
+			}
+			out = append(out, c)
+		}
+		return out
+	}
+	groups := []keymapGroup{group("a", 3), group("b", 2), group("c", 8), group("d", 1)}
+	// a takes 4 rows, b 1+3, so a and b fit in 10 rows but c doesn't
+	want := [][]string{{"a", "b"}, {"c"}, {"d"}}
+	if got := names(keymapColumns(groups, 10)); !reflect.DeepEqual(got, want) {
+		t.Errorf("keymapColumns(10) = %v, want %v", got, want)
+	}
+	// a group too long for a column still gets one
+	want = [][]string{{"a"}, {"b"}, {"c"}, {"d"}}
+	if got := names(keymapColumns(groups, 4)); !reflect.DeepEqual(got, want) {
+		t.Errorf("keymapColumns(4) = %v, want %v", got, want)
+	}
+	if got := keymapColumns(nil, 10); len(got) != 0 {
+		t.Errorf("keymapColumns(nil) = %v, want none", got)
+	}
+}
+
+func TestFormatKeymap(t *testing.T) {
+	groups := []keymapGroup{{"Sizing", []keymapEntry{{"Ctrl + Alt + ENTER", "Move By ({dx: 1} <a|b>)"}}}}
+	md, err := formatKeymap(groups, "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the output is redirected to files, so nothing may come before it
+	if !strings.HasPrefix(md, "# RectangleWin Plus Hotkeys\n") {
+		t.Errorf("markdown keymap doesn't start with its title:\n%s", md)
+	}
+	for _, want := range []string{"## Sizing\n", "| `Ctrl + Alt + ENTER` | Move By ({dx: 1} <a\\|b>) |\n"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown keymap lacks %q:\n%s", want, md)
+		}
+	}
+	page, err := formatKeymap(groups, "HTML")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(page, "<h1>RectangleWin Plus Hotkeys</h1>\n") {
+		t.Errorf("html keymap doesn't start with its title:\n%s", page)
+	}
+	for _, want := range []string{"<h2>Sizing</h2>", "<td>Move By ({dx: 1} &lt;a|b&gt;)</td>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("html keymap lacks %q:\n%s", want, page)
+		}
+	}
+	if _, err := formatKeymap(groups, "pdf"); err == nil {
+		t.Error("formatKeymap(pdf) expected error")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// The window side of cheatsheet.go: the cheat sheet drawn in the overlay
+// as columns of groups, each a table of hotkeys and feature names.
+
+const (
+	cheatSheetPollMs    = 100
+	cheatSheetHeadColor = 0x00F0C070
+	cheatSheetColumnGap = 32
+	cheatSheetKeyGap    = 16
+	cheatSheetTextFlags = w32.DT_SINGLELINE | w32.DT_NOPREFIX
+)
+
+var (
+	cheatSheetTimer uintptr
+	// set when the cheat sheet was opened with modifiers held, to hide it
+	// once they are released
+	cheatSheetHeld bool
+)
+
+// cheatSheet is the cheatSheet feature. It may be called from any thread,
+// so it leaves showing the cheat sheet to the msgLoop thread.
+func cheatSheet() {
+	w32ex.PostThreadMessage(msgLoopThread, msgShowCheatSheet, 0, 0)
+}
+
+func modifiersHeld() bool {
+	for _, vk := range []int{w32.VK_CONTROL, w32.VK_MENU, w32.VK_SHIFT, w32.VK_LWIN, w32.VK_RWIN} {
+		if w32.GetAsyncKeyState(vk)&0x8000 != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// showCheatSheet shows the cheat sheet until the modifiers of the hotkey
+// that opened it are released or, opened from the tray with none held,
+// until Escape, a modifier or a mouse button is pressed.
+func showCheatSheet() {
+	groups := keymapGroups(hks)
+	footer := "Change the hotkeys in the Settings UI or config.yaml."
+	if path, err := getValidConfigPathOrCreate(); err == nil {
+		footer = fmt.Sprintf("Change the hotkeys in the Settings UI or %s.", path)
+	}
+	var columns [][]keymapGroup
+	var keyWidths, colWidths []int32
+	var rowH int32
+	measure := func(hdc w32.HDC, workArea w32.RECT) (int32, int32) {
+		footerW, h := textSize(hdc, footer)
+		rowH = h + 2
+		maxRows := int(workArea.Height()/rowH) - 2
+		if maxRows < 1 {
+			maxRows = 1
+		}
+		columns = keymapColumns(groups, maxRows)
+		keyWidths = make([]int32, len(columns))
+		colWidths = make([]int32, len(columns))
+		width := -int32(cheatSheetColumnGap)
+		rows := 0
+		for c, col := range columns {
+			n := -1 // no blank row before the first group
+			for _, g := range col {
+				for _, e := range g.Entries {
+					w, _ := textSize(hdc, e.Keys)
+					keyWidths[c] = max(keyWidths[c], w)
+				}
+				n += len(g.Entries) + 2
+			}
+			for _, g := range col {
+				w, _ := textSize(hdc, g.Name)
+				colWidths[c] = max(colWidths[c], w)
+				for _, e := range g.Entries {
+					w, _ := textSize(hdc, e.Name)
+					colWidths[c] = max(colWidths[c], keyWidths[c]+cheatSheetKeyGap+w)
+				}
+			}
+			width += colWidths[c] + cheatSheetColumnGap
+			if n > rows {
+				rows = n
+			}
+		}
+		// a blank row and the footer below the columns
+		return max(width, footerW), int32(rows+2) * rowH
+	}
+	paint := func(hdc w32.HDC, rc *w32.RECT) {
+		draw := func(s string, left, top int32, color w32.COLORREF) {
+			r := w32.RECT{Left: left, Top: top, Right: rc.Right, Bottom: top + rowH}
+			w32.SetTextColor(hdc, color)
+			w32.DrawText(hdc, s, &r, cheatSheetTextFlags)
+		}
+		x := rc.Left
+		for c, col := range columns {
+			y := rc.Top
+			for i, g := range col {
+				if i > 0 {
+					y += rowH
+				}
+				draw(g.Name, x, y, cheatSheetHeadColor)
+				y += rowH
+				for _, e := range g.Entries {
+					draw(e.Keys, x, y, overlayFgColor)
+					draw(e.Name, x+keyWidths[c]+cheatSheetKeyGap, y, pickerDimColor)
+					y += rowH
+				}
+			}
+			x += colWidths[c] + cheatSheetColumnGap
+		}
+		draw(footer, rc.Left, rc.Bottom-rowH, pickerDimColor)
+	}
+	showOverlayWith(measure, paint)
+	cheatSheetHeld = modifiersHeld()
+	if cheatSheetTimer == 0 {
+		cheatSheetTimer = w32.SetTimer(0, 0, cheatSheetPollMs, 0)
+	}
+}
+
+func cheatSheetTick() {
+	if cheatSheetHeld {
+		if modifiersHeld() {
+			return
+		}
+	} else if !modifiersHeld() && w32.GetAsyncKeyState(w32.VK_ESCAPE)&0x8000 == 0 &&
+		w32.GetAsyncKeyState(w32.VK_LBUTTON)&0x8000 == 0 && w32.GetAsyncKeyState(w32.VK_RBUTTON)&0x8000 == 0 {
+		return
+	}
+	hideCheatSheet()
+}
+
+func hideCheatSheet() {
+	w32ex.KillTimer(0, cheatSheetTimer)
+	cheatSheetTimer = 0
+	hideOverlay()
+}
+
+func textSize(hdc w32.HDC, s string) (int32, int32) {
+	r := w32.RECT{}
+	w32.DrawText(hdc, s, &r, cheatSheetTextFlags|w32.DT_CALCRECT)
+	return r.Width(), r.Height()
+}
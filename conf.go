@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/gonutz/w32/v2"
@@ -24,6 +25,8 @@ type KeyBinding struct {
 	CombinedMod int32
 	// Valid values are:
 	//   A - Z, 0 - 9, UP_ARROW, =, -
+	//   MOUSE_LEFT, MOUSE_RIGHT, MOUSE_MIDDLE, MOUSE_X1, MOUSE_X2,
+	//   WHEEL_UP, WHEEL_DOWN (caught by a mouse hook, see mouse.go)
 	// Anything not covered here could be set directly via KeyCode
 	Key string `yaml:"key"`
 	// Automatically converted from Key.
@@ -49,14 +52,327 @@ type KeyBinding struct {
 	//   moveToCenter
 	//   toggleAlwaysOnTop
 	//   almostMaximize
+	//   growLeft, growRight, growTop, growBottom
+	//   shrinkLeft, shrinkRight, shrinkTop, shrinkBottom
+	//   tileAll
+	//   tileAllVertical
+	//   tileAllHorizontal
+	//   cascadeAll
+	//   undo
+	//   swapWithNext
+	//   swapLeft
+	//   swapRight
+	//   swapUp
+	//   swapDown
+	//   focusLeft
+	//   focusRight
+	//   focusUp
+	//   focusDown
+	//   leader
+	//   adjust
+	//   toggleTiling
+	//   cycleTilingLayout
+	//   promoteToMaster
+	//   swapTileNext
+	//   swapTilePrev
+	//   growMaster
+	//   shrinkMaster
+	//   size:<name> for each entry of sizePresets
+	//   moveToNextDesktop
+	//   moveToPrevDesktop
+	//   minimize
+	//   minimizeOthers
+	//   restoreAllMinimized
+	//   hideToTray
+	//   toggleTransparency
+	//   opacityUp
+	//   opacityDown
+	//   toggleClickThrough
+	//   pictureInPicture
+	//   windowPicker
+	//   commandPalette
+	//   cheatSheet
+	//   centerHalf
+	//   firstThird, centerThird, lastThird
+	//   firstTwoThirds, centerTwoThirds, lastTwoThirds
+	//   firstFourth, secondFourth, thirdFourth, lastFourth
+	//   firstThreeFourths, centerThreeFourths, lastThreeFourths
+	//   topLeftSixth, topCenterSixth, topRightSixth,
+	//   bottomLeftSixth, bottomCenterSixth, bottomRightSixth
+	//   topLeftEighth, topCenterLeftEighth, topCenterRightEighth, topRightEighth,
+	//   bottomLeftEighth, bottomCenterLeftEighth, bottomCenterRightEighth, bottomRightEighth
+	//
+	// Features that take arguments are written as a single-entry mapping
+	// from the feature name to its arguments:
+	//   bindfeature: {moveToDisplay: 2}
+	//   bindfeature: {moveToDesktop: 3}
+	//   bindfeature: {resizeBy: {dx: 100, dy: 0}}
+	//   bindfeature: {moveBy: {dx: 0, dy: -50}}
 	//
 	BindFeature string `yaml:"bindfeature"`
+	// Arguments for BindFeature, if any. Read from and written back to the
+	// bindfeature mapping form by UnmarshalYAML and MarshalYAML.
+	Args *yaml.Node `yaml:"-"`
+}
+
+// UnmarshalYAML accepts bindfeature either as a plain feature name or as a
+// single-entry mapping from the feature name to its arguments.
+func (kb *KeyBinding) UnmarshalYAML(value *yaml.Node) error {
+	type plain KeyBinding
+	var args *yaml.Node
+	if value.Kind == yaml.MappingNode {
+		// work on a copy so the caller's node is left untouched
+		copied := *value
+		copied.Content = append([]*yaml.Node(nil), value.Content...)
+		for i := 0; i+1 < len(copied.Content); i += 2 {
+			feature := copied.Content[i+1]
+			if copied.Content[i].Value != "bindfeature" || feature.Kind != yaml.MappingNode {
+				continue
+			}
+			if len(feature.Content) != 2 {
+				return fmt.Errorf("line %d: bindfeature must name exactly one feature", feature.Line)
+			}
+			copied.Content[i+1] = feature.Content[0]
+			args = feature.Content[1]
+		}
+		value = &copied
+	}
+	if err := value.Decode((*plain)(kb)); err != nil {
+		return err
+	}
+	kb.Args = args
+	return nil
+}
+
+// MarshalYAML writes bindings with arguments back in the mapping form.
+func (kb KeyBinding) MarshalYAML() (interface{}, error) {
+	type plain KeyBinding
+	if kb.Args == nil {
+		return plain(kb), nil
+	}
+	var node yaml.Node
+	if err := node.Encode(plain(kb)); err != nil {
+		return nil, err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "bindfeature" {
+			node.Content[i+1] = &yaml.Node{
+				Kind:    yaml.MappingNode,
+				Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: kb.BindFeature}, kb.Args},
+			}
+		}
+	}
+	return &node, nil
+}
+
+// LeaderConfig describes the second stage of the leader-key mode.
+// The leader itself is bound like any other feature (bindfeature: leader);
+// once pressed, the keys listed here are registered until one of them is
+// pressed, Escape is pressed, or the timeout expires.
+type LeaderConfig struct {
+	// How long to wait for the second key, in milliseconds.
+	// Defaults to DEFAULT_LEADER_TIMEOUT_MS when unset.
+	TimeoutMs int `yaml:"timeout_ms"`
+	// Second-stage bindings. Modifiers are optional and usually omitted.
+	Keys []KeyBinding `yaml:"keys"`
+}
+
+// DragConfig controls moving and resizing windows by dragging them with a
+// modifier held, see drag.go.
+type DragConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Modifiers to hold while dragging. Defaults to Alt.
+	Modifier []string `yaml:"modifier,omitempty"`
+	// Buttons that move and resize the window under the cursor, e.g.
+	// MOUSE_LEFT. Default to MOUSE_LEFT and MOUSE_RIGHT; "none" turns
+	// the action off.
+	MoveButton   string `yaml:"move_button,omitempty"`
+	ResizeButton string `yaml:"resize_button,omitempty"`
+	// Snap moved windows to the edges of the work area when they come
+	// within SnapDistance pixels of them.
+	SnapToEdges  bool  `yaml:"snap_to_edges,omitempty"`
+	SnapDistance int32 `yaml:"snap_distance,omitempty"`
+
+	// Calculated from the fields above by parseDragConfig.
+	CombinedMod   int32 `yaml:"-"`
+	MoveKeyCode   int32 `yaml:"-"`
+	ResizeKeyCode int32 `yaml:"-"`
+}
+
+// AdjustConfig sets the step sizes of the keyboard adjust mode, in pixels.
+type AdjustConfig struct {
+	Step       int32 `yaml:"step,omitempty"`
+	ResizeStep int32 `yaml:"resize_step,omitempty"`
+}
+
+// TilingConfig controls automatic tiling, see tiler.go.
+type TilingConfig struct {
+	// master_stack or bsp
+	Layout string `yaml:"layout,omitempty"`
+	// Share of the work area given to the master window, in percent.
+	MasterPercent int32 `yaml:"master_percent,omitempty"`
+	// How much growMaster and shrinkMaster change MasterPercent.
+	RatioStep int32 `yaml:"ratio_step,omitempty"`
+	// 1-based displays to tile on startup.
+	Displays []int `yaml:"displays,omitempty"`
+}
+
+// LinkedEdgesConfig controls resizing windows that share an edge
+// together, see linked.go.
+type LinkedEdgesConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// How far apart, in pixels, two edges may be and still count as shared.
+	Tolerance int32 `yaml:"tolerance,omitempty"`
+}
+
+// ResizeConfig controls the features that resize a window step by step.
+type ResizeConfig struct {
+	// Step of growLeft, shrinkRight and the other edge features, in pixels
+	// ("40" or "40px") or as a percentage of the display ("5%").
+	EdgeStep string `yaml:"edge_step,omitempty"`
+	// How far makeLarger and makeSmaller move each edge, in the same format.
+	Step string `yaml:"step,omitempty"`
+	// What stays in place when makeLarger and makeSmaller resize a window:
+	// "center", "corner" (the nearest corner) or "edge" (a screen edge the
+	// window touches, else the center).
+	Anchor string `yaml:"anchor,omitempty"`
+	// makeSmaller stops at the minimum size; makeLarger stops at the
+	// maximum size, 0 meaning the size of the display.
+	MinWidth  int32 `yaml:"min_width,omitempty"`
+	MinHeight int32 `yaml:"min_height,omitempty"`
+	MaxWidth  int32 `yaml:"max_width,omitempty"`
+	MaxHeight int32 `yaml:"max_height,omitempty"`
+
+	// Calculated from the fields above by parseResizeConfig.
+	EdgeStepSize resizeStep    `yaml:"-"`
+	Options      resizeOptions `yaml:"-"`
+}
+
+// VirtualDesktopConfig controls how RectangleWin Plus treats Windows
+// virtual desktops.
+type VirtualDesktopConfig struct {
+	// Keep a separate undo history for tileAll and friends on each
+	// desktop, so undo only restores windows arranged on the current one.
+	PerDesktopUndo bool `yaml:"per_desktop_undo,omitempty"`
+}
+
+// OpacityConfig sets the opacities, in percent, used by toggleTransparency,
+// opacityUp and opacityDown.
+type OpacityConfig struct {
+	// How much opacityUp and opacityDown change the opacity.
+	Step int32 `yaml:"step,omitempty"`
+	// The opacity toggleTransparency gives a window.
+	Transparent int32 `yaml:"transparent,omitempty"`
+	// opacityDown stops here so the window stays visible.
+	Min int32 `yaml:"min,omitempty"`
+}
+
+// PinnedConfig controls how windows pinned with toggleAlwaysOnTop are
+// shown and remembered, see pinned.go.
+type PinnedConfig struct {
+	// Draw a colored border around pinned windows.
+	Border bool `yaml:"border,omitempty"`
+	// Color of the border, such as "#0078D7".
+	BorderColor string `yaml:"border_color,omitempty"`
+	// Width of the border in pixels.
+	BorderWidth int32 `yaml:"border_width,omitempty"`
+	// Remember the apps pinned, pinning their windows again when they open
+	// until they are unpinned.
+	Remember bool `yaml:"remember,omitempty"`
+
+	// Calculated from BorderColor by parsePinnedConfig.
+	Color w32.COLORREF `yaml:"-"`
+}
+
+// PictureInPictureConfig controls pictureInPicture, see pip.go.
+type PictureInPictureConfig struct {
+	// Size of the window in logical pixels, scaled by the display's DPI,
+	// such as "480x270".
+	Size string `yaml:"size,omitempty"`
+	// top_left, top_right, bottom_left or bottom_right
+	Corner string `yaml:"corner,omitempty"`
+	// Distance from the edges of the work area, in logical pixels.
+	Margin int32 `yaml:"margin,omitempty"`
+	// Opacity in percent, 100 leaving the window opaque.
+	Opacity int32 `yaml:"opacity,omitempty"`
+
+	// Calculated from Size by parsePictureInPictureConfig.
+	Width  int32 `yaml:"-"`
+	Height int32 `yaml:"-"`
+}
+
+// PickerConfig sets the features the window picker runs on the chosen
+// window when Enter is pressed with a modifier.
+type PickerConfig struct {
+	CtrlEnter  string `yaml:"ctrl_enter,omitempty"`
+	ShiftEnter string `yaml:"shift_enter,omitempty"`
+	AltEnter   string `yaml:"alt_enter,omitempty"`
+}
+
+// HotkeysConfig controls what happens to hotkeys that fail to register
+// because another program holds them, see hotkey_failures.go.
+type HotkeysConfig struct {
+	// How often to retry them, in seconds. Negative only retries them from
+	// the tray menu.
+	RetrySeconds int `yaml:"retry_seconds,omitempty"`
+	// Catch them with a low-level keyboard hook instead, taking them from
+	// the other program.
+	KeyboardHook bool `yaml:"keyboard_hook,omitempty"`
+}
+
+// SizePreset is a named window size, bound as the feature "size:" + Name.
+type SizePreset struct {
+	Name string `yaml:"name"`
+	// Either an exact size such as "1280x720", or an aspect ratio such as
+	// "16:9" which gives the largest window of that shape that fits.
+	Size  string `yaml:"size,omitempty"`
+	Ratio string `yaml:"ratio,omitempty"`
+	// Whether Size is in logical pixels, scaled by the display's DPI.
+	Logical bool `yaml:"logical,omitempty"`
+	// "center" (the default) centers the window on the display, "top_left"
+	// keeps its top-left corner in place.
+	Anchor string `yaml:"anchor,omitempty"`
 }
 
 type Configuration struct {
-	Keybindings []KeyBinding `yaml:"keybindings"`
+	Keybindings []KeyBinding      `yaml:"keybindings"`
+	Leader      LeaderConfig      `yaml:"leader"`
+	Drag        DragConfig        `yaml:"drag,omitempty"`
+	Adjust      AdjustConfig      `yaml:"adjust,omitempty"`
+	Tiling      TilingConfig      `yaml:"tiling,omitempty"`
+	LinkedEdges LinkedEdgesConfig `yaml:"linked_edges,omitempty"`
+	Resize      ResizeConfig      `yaml:"resize,omitempty"`
+	SizePresets []SizePreset      `yaml:"sizePresets,omitempty"`
+	Opacity     OpacityConfig     `yaml:"opacity,omitempty"`
+
+	PictureInPicture PictureInPictureConfig `yaml:"picture_in_picture,omitempty"`
+	Pinned           PinnedConfig           `yaml:"pinned,omitempty"`
+	Picker           PickerConfig           `yaml:"picker,omitempty"`
+	Hotkeys          HotkeysConfig          `yaml:"hotkeys,omitempty"`
+
+	VirtualDesktops VirtualDesktopConfig `yaml:"virtual_desktops,omitempty"`
 }
 
+const DEFAULT_LEADER_TIMEOUT_MS = 2000
+const DEFAULT_DRAG_SNAP_DISTANCE = 16
+const DEFAULT_ADJUST_STEP = 10
+const DEFAULT_ADJUST_RESIZE_STEP = 20
+const DEFAULT_TILING_MASTER_PERCENT = 55
+const DEFAULT_TILING_RATIO_STEP = 5
+const DEFAULT_LINKED_EDGE_TOLERANCE = 8
+const DEFAULT_RESIZE_STEP = "5%"
+const DEFAULT_RESIZE_ANCHOR = anchorEdge
+const DEFAULT_OPACITY_STEP = 10
+const DEFAULT_OPACITY_TRANSPARENT = 70
+const DEFAULT_OPACITY_MIN = 20
+const DEFAULT_PIP_SIZE = "480x270"
+const DEFAULT_PIP_WIDTH = 480
+const DEFAULT_PIP_HEIGHT = 270
+const DEFAULT_PIP_MARGIN = 16
+const DEFAULT_PIN_BORDER_COLOR = "#0078D7"
+const DEFAULT_PIN_BORDER_WIDTH = 3
+const DEFAULT_HOTKEY_RETRY_SECONDS = 60
+
 // This mini config is returned if we can't load a valid file
 // and cannot write the detailed example yaml config.example.yaml
 // into the expected path at %HOME%
@@ -118,6 +434,24 @@ func convertKeyCode(key string) (int32, error) {
 		return 187, nil
 	case "|", "\\":
 		return 124, nil
+	case "space":
+		return w32.VK_SPACE, nil
+	case "escape", "esc":
+		return w32.VK_ESCAPE, nil
+	case "mouse_left":
+		return w32.VK_LBUTTON, nil
+	case "mouse_right":
+		return w32.VK_RBUTTON, nil
+	case "mouse_middle":
+		return w32.VK_MBUTTON, nil
+	case "mouse_x1":
+		return w32.VK_XBUTTON1, nil
+	case "mouse_x2":
+		return w32.VK_XBUTTON2, nil
+	case "wheel_up":
+		return VK_WHEEL_UP, nil
+	case "wheel_down":
+		return VK_WHEEL_DOWN, nil
 	}
 	for id, v := range keyNames {
 		lv := strings.ToLower(v)
@@ -185,42 +519,352 @@ func fetchConfiguration() Configuration {
 	if err != nil {
 		fmt.Printf("Failed to load config file at expected path %s\n", configFilePath)
 		// use the last-ditch config
-		return DEFAULT_CONF
+		return parseConfiguration(DEFAULT_CONF)
 	}
 
 	if err := yaml.Unmarshal(data, &myConfig); err != nil {
 		showMessageBox("Failed to parse config file at %s.\n")
-		return DEFAULT_CONF
+		return parseConfiguration(DEFAULT_CONF)
 	}
 	myConfig = parseConfiguration(myConfig)
 	return myConfig
 }
 
+// replaceKeybindings returns the config file data with its keybindings
+// replaced by kbs. Every other section stays as the user wrote it, rather
+// than as parsed with the defaults filled in, so saving the settings
+// doesn't freeze today's defaults into the file.
+func replaceKeybindings(data []byte, kbs []KeyBinding) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		// an empty file
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("config file is not a mapping")
+	}
+	var value yaml.Node
+	if err := value.Encode(kbs); err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "keybindings" {
+			root.Content[i+1] = &value
+			return yaml.Marshal(&doc)
+		}
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "keybindings"}
+	root.Content = append(root.Content, key, &value)
+	return yaml.Marshal(&doc)
+}
+
 func parseConfiguration(myConfig Configuration) Configuration {
 	for i := range myConfig.Keybindings {
-		// handle alias
-		if myConfig.Keybindings[i].BindFeature == "previousDisplay" {
-			myConfig.Keybindings[i].BindFeature = "prevDisplay"
+		parseKeyBinding(&myConfig.Keybindings[i])
+	}
+	for i := range myConfig.Leader.Keys {
+		parseKeyBinding(&myConfig.Leader.Keys[i])
+	}
+	if myConfig.Leader.TimeoutMs <= 0 {
+		myConfig.Leader.TimeoutMs = DEFAULT_LEADER_TIMEOUT_MS
+	}
+	parseDragConfig(&myConfig.Drag)
+	if myConfig.Adjust.Step <= 0 {
+		myConfig.Adjust.Step = DEFAULT_ADJUST_STEP
+	}
+	if myConfig.Adjust.ResizeStep <= 0 {
+		myConfig.Adjust.ResizeStep = DEFAULT_ADJUST_RESIZE_STEP
+	}
+	parseTilingConfig(&myConfig.Tiling)
+	if myConfig.LinkedEdges.Tolerance <= 0 {
+		myConfig.LinkedEdges.Tolerance = DEFAULT_LINKED_EDGE_TOLERANCE
+	}
+	parseResizeConfig(&myConfig.Resize)
+	parseOpacityConfig(&myConfig.Opacity)
+	parsePictureInPictureConfig(&myConfig.PictureInPicture)
+	parsePinnedConfig(&myConfig.Pinned)
+	if myConfig.Hotkeys.RetrySeconds == 0 {
+		myConfig.Hotkeys.RetrySeconds = DEFAULT_HOTKEY_RETRY_SECONDS
+	}
+	return myConfig
+}
+
+func parseResizeConfig(rc *ResizeConfig) {
+	step, err := parseResizeStep(rc.EdgeStep)
+	if err != nil {
+		fmt.Printf("warn: edge_step: %v\n", err)
+		step, _ = parseResizeStep(DEFAULT_RESIZE_STEP)
+	}
+	rc.EdgeStepSize = step
+
+	if rc.Step == "" {
+		rc.Step = DEFAULT_RESIZE_STEP
+	}
+	step, err = parseResizeStep(rc.Step)
+	if err != nil {
+		fmt.Printf("warn: resize step: %v\n", err)
+		step, _ = parseResizeStep(DEFAULT_RESIZE_STEP)
+	}
+	switch resizeAnchor(rc.Anchor) {
+	case anchorCenter, anchorCorner, anchorEdge:
+	case "":
+		rc.Anchor = string(DEFAULT_RESIZE_ANCHOR)
+	default:
+		fmt.Printf("warn: unknown resize anchor %q, using %q\n", rc.Anchor, DEFAULT_RESIZE_ANCHOR)
+		rc.Anchor = string(DEFAULT_RESIZE_ANCHOR)
+	}
+	if rc.MinWidth <= 0 {
+		rc.MinWidth = minWindowSize
+	}
+	if rc.MinHeight <= 0 {
+		rc.MinHeight = minWindowSize
+	}
+	if rc.MaxWidth < 0 {
+		rc.MaxWidth = 0
+	}
+	if rc.MaxHeight < 0 {
+		rc.MaxHeight = 0
+	}
+	if rc.MaxWidth > 0 && rc.MaxWidth < rc.MinWidth {
+		fmt.Printf("warn: resize max_width %d below min_width %d, ignoring it\n", rc.MaxWidth, rc.MinWidth)
+		rc.MaxWidth = 0
+	}
+	if rc.MaxHeight > 0 && rc.MaxHeight < rc.MinHeight {
+		fmt.Printf("warn: resize max_height %d below min_height %d, ignoring it\n", rc.MaxHeight, rc.MinHeight)
+		rc.MaxHeight = 0
+	}
+	rc.Options = resizeOptions{
+		step:      step,
+		anchor:    resizeAnchor(rc.Anchor),
+		minWidth:  rc.MinWidth,
+		minHeight: rc.MinHeight,
+		maxWidth:  rc.MaxWidth,
+		maxHeight: rc.MaxHeight,
+	}
+}
+
+// parseResizeStep reads a step such as "40", "40px" or "5%". An empty
+// string gives the default step.
+func parseResizeStep(s string) (resizeStep, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		s = DEFAULT_RESIZE_STEP
+	}
+	var n int32
+	percent := strings.HasSuffix(s, "%")
+	num := strings.TrimSuffix(strings.TrimSuffix(s, "%"), "px")
+	if _, err := fmt.Sscanf(num, "%d", &n); err != nil || fmt.Sprint(n) != strings.TrimSpace(num) || n <= 0 {
+		return resizeStep{}, fmt.Errorf("invalid step %q, want pixels like 40px or a percentage like 5%%", s)
+	}
+	if percent {
+		if n > 100 {
+			return resizeStep{}, fmt.Errorf("invalid step %q, percentage above 100", s)
 		}
-		if len(myConfig.Keybindings[i].ModifierCode) == 0 {
-			for _, mod := range myConfig.Keybindings[i].Modifier {
-				if modCode, err := convertModifier(mod); err == nil {
-					myConfig.Keybindings[i].ModifierCode = append(myConfig.Keybindings[i].ModifierCode, modCode)
-				} else {
-					fmt.Printf("warn: invalid key name %s", mod)
-					continue
-				}
-			}
+		return resizeStep{Percent: n}, nil
+	}
+	return resizeStep{Pixels: n}, nil
+}
+
+// parseSizePresets checks the presets, dropping any that are invalid.
+func parseSizePresets(presets []SizePreset) []sizePreset {
+	var parsed []sizePreset
+	for _, sp := range presets {
+		p, err := parseSizePreset(sp)
+		if err != nil {
+			fmt.Printf("warn: size preset %q: %v\n", sp.Name, err)
+			continue
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed
+}
+
+func parseSizePreset(sp SizePreset) (sizePreset, error) {
+	p := sizePreset{name: strings.TrimSpace(sp.Name), logical: sp.Logical}
+	if p.name == "" {
+		return p, errors.New("missing name")
+	}
+	switch anchor := strings.ToLower(strings.TrimSpace(sp.Anchor)); anchor {
+	case "", string(presetAnchorCenter):
+		p.anchor = presetAnchorCenter
+	case string(presetAnchorTopLeft):
+		p.anchor = presetAnchorTopLeft
+	default:
+		return p, fmt.Errorf("invalid anchor %q, want center or top_left", sp.Anchor)
+	}
+	size, ratio := strings.TrimSpace(sp.Size), strings.TrimSpace(sp.Ratio)
+	if (size == "") == (ratio == "") {
+		return p, errors.New("set exactly one of size and ratio")
+	}
+	var err error
+	if size != "" {
+		p.width, p.height, err = parsePair(size, "x")
+	} else {
+		p.ratioW, p.ratioH, err = parsePair(ratio, ":")
+	}
+	return p, err
+}
+
+// parsePair reads two positive numbers separated by sep, like 1280x720.
+func parsePair(s, sep string) (int32, int32, error) {
+	parts := strings.Split(strings.ToLower(s), sep)
+	if len(parts) == 2 {
+		a, errA := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 32)
+		b, errB := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 32)
+		if errA == nil && errB == nil && a > 0 && b > 0 {
+			return int32(a), int32(b), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid value %q, want two positive numbers like 16%s9", s, sep)
+}
+
+func parseOpacityConfig(oc *OpacityConfig) {
+	if oc.Step <= 0 || oc.Step >= 100 {
+		oc.Step = DEFAULT_OPACITY_STEP
+	}
+	if oc.Min <= 0 || oc.Min >= 100 {
+		oc.Min = DEFAULT_OPACITY_MIN
+	}
+	if oc.Transparent <= 0 || oc.Transparent >= 100 {
+		oc.Transparent = DEFAULT_OPACITY_TRANSPARENT
+	}
+	oc.Transparent = max(oc.Min, oc.Transparent)
+}
+
+func parsePictureInPictureConfig(pc *PictureInPictureConfig) {
+	if pc.Size == "" {
+		pc.Size = DEFAULT_PIP_SIZE
+	}
+	var err error
+	if pc.Width, pc.Height, err = parsePair(pc.Size, "x"); err != nil {
+		fmt.Printf("warn: picture_in_picture size: %v\n", err)
+		pc.Size, pc.Width, pc.Height = DEFAULT_PIP_SIZE, DEFAULT_PIP_WIDTH, DEFAULT_PIP_HEIGHT
+	}
+	if pc.Corner == "" {
+		pc.Corner = string(pipBottomRight)
+	} else if !pipCorner(pc.Corner).valid() {
+		fmt.Printf("warn: invalid picture_in_picture corner %s\n", pc.Corner)
+		pc.Corner = string(pipBottomRight)
+	}
+	if pc.Margin <= 0 {
+		pc.Margin = DEFAULT_PIP_MARGIN
+	}
+	if pc.Opacity <= 0 || pc.Opacity > 100 {
+		pc.Opacity = 100
+	}
+}
+
+func parsePinnedConfig(pc *PinnedConfig) {
+	if pc.BorderColor == "" {
+		pc.BorderColor = DEFAULT_PIN_BORDER_COLOR
+	}
+	color, err := parseColor(pc.BorderColor)
+	if err != nil {
+		fmt.Printf("warn: pinned border_color: %v\n", err)
+		pc.BorderColor = DEFAULT_PIN_BORDER_COLOR
+		color, _ = parseColor(DEFAULT_PIN_BORDER_COLOR)
+	}
+	pc.Color = color
+	if pc.BorderWidth <= 0 {
+		pc.BorderWidth = DEFAULT_PIN_BORDER_WIDTH
+	}
+}
+
+// parseColor parses an HTML color such as "#0078D7".
+func parseColor(s string) (w32.COLORREF, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return 0, fmt.Errorf("invalid color %q, want one like #0078D7", s)
+	}
+	r, g, b := rgb>>16, rgb>>8&0xFF, rgb&0xFF
+	// COLORREF is 0x00BBGGRR
+	return w32.COLORREF(b<<16 | g<<8 | r), nil
+}
+
+func parseTilingConfig(tc *TilingConfig) {
+	if tc.Layout == "" {
+		tc.Layout = string(layoutMasterStack)
+	} else if !tilingLayout(tc.Layout).valid() {
+		fmt.Printf("warn: invalid tiling layout %s\n", tc.Layout)
+		tc.Layout = string(layoutMasterStack)
+	}
+	if tc.MasterPercent == 0 {
+		tc.MasterPercent = DEFAULT_TILING_MASTER_PERCENT
+	}
+	tc.MasterPercent = clampMasterPercent(tc.MasterPercent)
+	if tc.RatioStep <= 0 {
+		tc.RatioStep = DEFAULT_TILING_RATIO_STEP
+	}
+}
+
+func parseDragConfig(dc *DragConfig) {
+	modifiers := dc.Modifier
+	if len(modifiers) == 0 {
+		modifiers = []string{"alt"}
+	}
+	dc.CombinedMod = 0
+	for _, mod := range modifiers {
+		if modCode, err := convertModifier(mod); err == nil {
+			dc.CombinedMod |= modCode
+		} else {
+			fmt.Printf("warn: invalid key name %s\n", mod)
 		}
-		myConfig.Keybindings[i].CombinedMod = bitwiseOr(myConfig.Keybindings[i].ModifierCode)
-		if myConfig.Keybindings[i].KeyCode == 0 {
-			if key, err := convertKeyCode(myConfig.Keybindings[i].Key); err == nil {
-				myConfig.Keybindings[i].KeyCode = key
+	}
+	if dc.CombinedMod == 0 {
+		// a bare click must keep working as a click
+		fmt.Println("warn: drag needs a modifier, disabling it")
+		dc.Enabled = false
+	}
+	dc.MoveKeyCode = parseDragButton(dc.MoveButton, "mouse_left")
+	dc.ResizeKeyCode = parseDragButton(dc.ResizeButton, "mouse_right")
+	if dc.SnapDistance <= 0 {
+		dc.SnapDistance = DEFAULT_DRAG_SNAP_DISTANCE
+	}
+}
+
+// parseDragButton returns the virtual key of a mouse button name, or 0 if
+// the action is turned off or the name is not a mouse button.
+func parseDragButton(name, def string) int32 {
+	if name == "" {
+		name = def
+	}
+	if strings.EqualFold(name, "none") {
+		return 0
+	}
+	vk, err := convertKeyCode(name)
+	if err != nil || !isMouseKey(int(vk)) || vk == VK_WHEEL_UP || vk == VK_WHEEL_DOWN {
+		fmt.Printf("warn: invalid drag button %s\n", name)
+		return 0
+	}
+	return vk
+}
+
+func parseKeyBinding(kb *KeyBinding) {
+	// handle alias
+	if kb.BindFeature == "previousDisplay" {
+		kb.BindFeature = "prevDisplay"
+	}
+	if len(kb.ModifierCode) == 0 {
+		for _, mod := range kb.Modifier {
+			if modCode, err := convertModifier(mod); err == nil {
+				kb.ModifierCode = append(kb.ModifierCode, modCode)
 			} else {
-				fmt.Printf("warn: invalid key string %s", myConfig.Keybindings[i].Key)
+				fmt.Printf("warn: invalid key name %s", mod)
 				continue
 			}
 		}
 	}
-	return myConfig
+	kb.CombinedMod = bitwiseOr(kb.ModifierCode)
+	if kb.KeyCode == 0 {
+		if key, err := convertKeyCode(kb.Key); err == nil {
+			kb.KeyCode = key
+		} else {
+			fmt.Printf("warn: invalid key string %s", kb.Key)
+		}
+	}
 }
@@ -3,8 +3,12 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/gonutz/w32/v2"
+	"gopkg.in/yaml.v3"
 )
 
 func TestConvertModifier(t *testing.T) {
@@ -57,6 +61,14 @@ func TestConvertKeyCode(t *testing.T) {
 		{"=", 187, false},
 		{"|", 124, false},
 		{"\\", 124, false},
+		{"SPACE", 0x20, false},
+		{"escape", 0x1B, false},
+		{"ENTER", 0x0D, false},
+		{"MOUSE_MIDDLE", 0x04, false},
+		{"mouse_x2", 0x06, false},
+		{"Middle mouse button", 0x04, false},
+		{"WHEEL_UP", VK_WHEEL_UP, false},
+		{"wheel_down", VK_WHEEL_DOWN, false},
 		{"invalidkey", 0, true},
 	}
 	for _, c := range cases {
@@ -155,6 +167,93 @@ func TestParseConfiguration(t *testing.T) {
 	}
 }
 
+func TestParseConfigurationLeader(t *testing.T) {
+	parsed := parseConfiguration(Configuration{
+		Leader: LeaderConfig{
+			Keys: []KeyBinding{
+				{Key: "L", BindFeature: "moveToLeft"},
+				{Modifier: []string{"Shift"}, Key: "1", BindFeature: "previousDisplay"},
+			},
+		},
+	})
+	if parsed.Leader.TimeoutMs != DEFAULT_LEADER_TIMEOUT_MS {
+		t.Errorf("expected default leader timeout %d, got %d", DEFAULT_LEADER_TIMEOUT_MS, parsed.Leader.TimeoutMs)
+	}
+	if parsed.Leader.Keys[0].KeyCode != int32('L') || parsed.Leader.Keys[0].CombinedMod != 0 {
+		t.Errorf("unexpected leader key %+v", parsed.Leader.Keys[0])
+	}
+	if parsed.Leader.Keys[1].KeyCode != int32('1') || parsed.Leader.Keys[1].CombinedMod != MOD_SHIFT {
+		t.Errorf("unexpected leader key %+v", parsed.Leader.Keys[1])
+	}
+	if parsed.Leader.Keys[1].BindFeature != "prevDisplay" {
+		t.Errorf("expected alias to resolve to 'prevDisplay', got '%s'", parsed.Leader.Keys[1].BindFeature)
+	}
+
+	parsed = parseConfiguration(Configuration{Leader: LeaderConfig{TimeoutMs: 750}})
+	if parsed.Leader.TimeoutMs != 750 {
+		t.Errorf("expected leader timeout 750, got %d", parsed.Leader.TimeoutMs)
+	}
+}
+
+func TestKeyBindingYAML(t *testing.T) {
+	src := `
+keybindings:
+  - key: L
+    bindfeature: moveToLeft
+  - key: "2"
+    bindfeature: {moveToDisplay: 2}
+  - key: "="
+    bindfeature:
+      resizeBy: {dx: 100}
+`
+	var config Configuration
+	if err := yaml.Unmarshal([]byte(src), &config); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v", err)
+	}
+	if len(config.Keybindings) != 3 {
+		t.Fatalf("expected 3 keybindings, got %d", len(config.Keybindings))
+	}
+	if kb := config.Keybindings[0]; kb.BindFeature != "moveToLeft" || kb.Args != nil || kb.Key != "L" {
+		t.Errorf("unexpected plain binding %+v", kb)
+	}
+	var n int
+	if kb := config.Keybindings[1]; kb.BindFeature != "moveToDisplay" || kb.Args == nil || kb.Args.Decode(&n) != nil || n != 2 {
+		t.Errorf("unexpected moveToDisplay binding %+v", kb)
+	}
+	var d deltaArgs
+	if kb := config.Keybindings[2]; kb.BindFeature != "resizeBy" || kb.Args == nil || kb.Args.Decode(&d) != nil || d.DX != 100 {
+		t.Errorf("unexpected resizeBy binding %+v", kb)
+	}
+	if config.Keybindings[2].Key != "=" {
+		t.Errorf("expected other fields to be decoded, got key %q", config.Keybindings[2].Key)
+	}
+
+	// round trip keeps the mapping form
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatalf("yaml.Marshal: %v", err)
+	}
+	var again Configuration
+	if err := yaml.Unmarshal(data, &again); err != nil {
+		t.Fatalf("yaml.Unmarshal round trip: %v\n%s", err, data)
+	}
+	if again.Keybindings[1].BindFeature != "moveToDisplay" || again.Keybindings[1].Args == nil {
+		t.Errorf("round trip lost arguments:\n%s", data)
+	}
+	if again.Keybindings[0].Args != nil {
+		t.Errorf("round trip added arguments to a plain binding:\n%s", data)
+	}
+
+	bad := `
+keybindings:
+  - key: L
+    bindfeature: {moveToLeft: 1, moveToRight: 2}
+`
+	if err := yaml.Unmarshal([]byte(bad), &config); err == nil {
+		t.Error("expected error for bindfeature naming two features")
+	}
+}
+
 func TestFetchConfiguration(t *testing.T) {
 	// Create a temporary home directory
 	tmpDir, err := os.MkdirTemp("", "conf_test_fetch")
@@ -216,3 +315,249 @@ keybindings:
 		t.Errorf("expected KeyCode %d, got %d", int32('Z'), config.Keybindings[0].KeyCode)
 	}
 }
+
+func TestParseDragConfig(t *testing.T) {
+	dc := DragConfig{Enabled: true}
+	parseDragConfig(&dc)
+	if !dc.Enabled || dc.CombinedMod != MOD_ALT || dc.MoveKeyCode != w32.VK_LBUTTON || dc.ResizeKeyCode != w32.VK_RBUTTON {
+		t.Errorf("defaults = %+v, want Alt, left to move, right to resize", dc)
+	}
+	if dc.SnapDistance != DEFAULT_DRAG_SNAP_DISTANCE {
+		t.Errorf("SnapDistance = %d, want %d", dc.SnapDistance, DEFAULT_DRAG_SNAP_DISTANCE)
+	}
+
+	dc = DragConfig{Enabled: true, Modifier: []string{"Win", "Shift"}, MoveButton: "MOUSE_MIDDLE", ResizeButton: "none", SnapDistance: 8}
+	parseDragConfig(&dc)
+	if dc.CombinedMod != MOD_WIN|MOD_SHIFT || dc.MoveKeyCode != w32.VK_MBUTTON || dc.ResizeKeyCode != 0 || dc.SnapDistance != 8 {
+		t.Errorf("parsed = %+v", dc)
+	}
+
+	dc = DragConfig{Enabled: true, MoveButton: "WHEEL_UP", ResizeButton: "A"}
+	parseDragConfig(&dc)
+	if dc.MoveKeyCode != 0 || dc.ResizeKeyCode != 0 {
+		t.Errorf("non-button keys should be rejected, got %+v", dc)
+	}
+
+	dc = DragConfig{Enabled: true, Modifier: []string{"bogus"}}
+	parseDragConfig(&dc)
+	if dc.Enabled {
+		t.Error("drag without a valid modifier should be disabled")
+	}
+}
+
+func TestParseTilingConfig(t *testing.T) {
+	tc := TilingConfig{}
+	parseTilingConfig(&tc)
+	if tc.Layout != "master_stack" || tc.MasterPercent != DEFAULT_TILING_MASTER_PERCENT || tc.RatioStep != DEFAULT_TILING_RATIO_STEP {
+		t.Errorf("defaults = %+v", tc)
+	}
+	tc = TilingConfig{Layout: "bsp", MasterPercent: 95, RatioStep: 10}
+	parseTilingConfig(&tc)
+	if tc.Layout != "bsp" || tc.MasterPercent != 90 || tc.RatioStep != 10 {
+		t.Errorf("parsed = %+v, want bsp, clamped to 90, step 10", tc)
+	}
+	tc = TilingConfig{Layout: "spiral", MasterPercent: 3}
+	parseTilingConfig(&tc)
+	if tc.Layout != "master_stack" || tc.MasterPercent != 10 {
+		t.Errorf("parsed = %+v, want master_stack and 10", tc)
+	}
+}
+
+func TestParseOpacityConfig(t *testing.T) {
+	oc := OpacityConfig{}
+	parseOpacityConfig(&oc)
+	if oc != (OpacityConfig{DEFAULT_OPACITY_STEP, DEFAULT_OPACITY_TRANSPARENT, DEFAULT_OPACITY_MIN}) {
+		t.Errorf("defaults = %+v", oc)
+	}
+	oc = OpacityConfig{Step: 5, Transparent: 10, Min: 30}
+	parseOpacityConfig(&oc)
+	if oc != (OpacityConfig{Step: 5, Transparent: 30, Min: 30}) {
+		t.Errorf("parsed = %+v, want transparent raised to the minimum", oc)
+	}
+	oc = OpacityConfig{Step: 100, Transparent: 150, Min: -1}
+	parseOpacityConfig(&oc)
+	if oc != (OpacityConfig{DEFAULT_OPACITY_STEP, DEFAULT_OPACITY_TRANSPARENT, DEFAULT_OPACITY_MIN}) {
+		t.Errorf("parsed = %+v, want defaults for out of range values", oc)
+	}
+}
+
+func TestParsePictureInPictureConfig(t *testing.T) {
+	pc := PictureInPictureConfig{}
+	parsePictureInPictureConfig(&pc)
+	want := PictureInPictureConfig{Size: "480x270", Corner: "bottom_right", Margin: DEFAULT_PIP_MARGIN, Opacity: 100, Width: 480, Height: 270}
+	if pc != want {
+		t.Errorf("defaults = %+v, want %+v", pc, want)
+	}
+	pc = PictureInPictureConfig{Size: "640 x 360", Corner: "top_left", Margin: 8, Opacity: 80}
+	parsePictureInPictureConfig(&pc)
+	if pc.Width != 640 || pc.Height != 360 || pc.Corner != "top_left" || pc.Margin != 8 || pc.Opacity != 80 {
+		t.Errorf("parsed = %+v", pc)
+	}
+	pc = PictureInPictureConfig{Size: "small", Corner: "middle", Opacity: 150}
+	parsePictureInPictureConfig(&pc)
+	if pc != want {
+		t.Errorf("invalid values = %+v, want defaults %+v", pc, want)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want w32.COLORREF
+		ok   bool
+	}{
+		{"#0078D7", 0x00D77800, true},
+		{" ff0000 ", 0x000000FF, true},
+		{"#00ff00", 0x0000FF00, true},
+		{"#0078D", 0, false},
+		{"blue", 0, false},
+		{"#12345678", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseColor(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseColor(%q) = 0x%06X, %v; want 0x%06X, ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParsePinnedConfig(t *testing.T) {
+	pc := PinnedConfig{BorderColor: "red", BorderWidth: -2}
+	parsePinnedConfig(&pc)
+	if pc.BorderColor != DEFAULT_PIN_BORDER_COLOR || pc.Color != 0x00D77800 || pc.BorderWidth != DEFAULT_PIN_BORDER_WIDTH {
+		t.Errorf("invalid values = %+v, want defaults", pc)
+	}
+}
+
+func TestParseResizeStep(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    resizeStep
+		wantErr bool
+	}{
+		{"", resizeStep{Percent: 5}, false},
+		{"40", resizeStep{Pixels: 40}, false},
+		{"40px", resizeStep{Pixels: 40}, false},
+		{" 10% ", resizeStep{Percent: 10}, false},
+		{"0", resizeStep{}, true},
+		{"-5%", resizeStep{}, true},
+		{"150%", resizeStep{}, true},
+		{"lots", resizeStep{}, true},
+		{"4x0", resizeStep{}, true},
+	}
+	for _, c := range cases {
+		got, err := parseResizeStep(c.in)
+		if (err != nil) != c.wantErr || !c.wantErr && got != c.want {
+			t.Errorf("parseResizeStep(%q) = %+v, %v, want %+v, wantErr %v", c.in, got, err, c.want, c.wantErr)
+		}
+	}
+	rc := ResizeConfig{EdgeStep: "bogus"}
+	parseResizeConfig(&rc)
+	if rc.EdgeStepSize != (resizeStep{Percent: 5}) {
+		t.Errorf("invalid edge_step should fall back to the default, got %+v", rc.EdgeStepSize)
+	}
+	if rc.Options != (resizeOptions{step: resizeStep{Percent: 5}, anchor: anchorEdge, minWidth: minWindowSize, minHeight: minWindowSize}) {
+		t.Errorf("default resize options = %+v", rc.Options)
+	}
+
+	rc = ResizeConfig{Step: "30px", Anchor: "corner", MinWidth: 200, MaxWidth: 150, MaxHeight: 800}
+	parseResizeConfig(&rc)
+	want := resizeOptions{step: resizeStep{Pixels: 30}, anchor: anchorCorner, minWidth: 200, minHeight: minWindowSize, maxHeight: 800}
+	if rc.Options != want {
+		t.Errorf("resize options = %+v, want %+v", rc.Options, want)
+	}
+	rc = ResizeConfig{Anchor: "middle"}
+	parseResizeConfig(&rc)
+	if rc.Options.anchor != anchorEdge {
+		t.Errorf("unknown anchor should fall back to edge, got %q", rc.Options.anchor)
+	}
+}
+
+func TestParseSizePresets(t *testing.T) {
+	got := parseSizePresets([]SizePreset{
+		{Name: "hd", Size: "1280x720"},
+		{Name: " wide ", Ratio: "16:9", Anchor: "TOP_LEFT"},
+		{Name: "small", Size: "800 x 600", Logical: true},
+		{Name: "", Size: "1280x720"},
+		{Name: "both", Size: "1280x720", Ratio: "16:9"},
+		{Name: "neither"},
+		{Name: "zero", Size: "0x720"},
+		{Name: "bad", Ratio: "16/9"},
+		{Name: "anchor", Size: "10x10", Anchor: "bottom"},
+	})
+	want := []sizePreset{
+		{name: "hd", width: 1280, height: 720, anchor: presetAnchorCenter},
+		{name: "wide", ratioW: 16, ratioH: 9, anchor: presetAnchorTopLeft},
+		{name: "small", width: 800, height: 600, logical: true, anchor: presetAnchorCenter},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSizePresets = %+v, want %+v", got, want)
+	}
+}
+
+func TestSizePresetsYAML(t *testing.T) {
+	var config Configuration
+	src := `
+keybindings: []
+sizePresets:
+  - name: hd
+    size: 1280x720
+  - name: wide
+    ratio: "16:9"
+    anchor: top_left
+`
+	if err := yaml.Unmarshal([]byte(src), &config); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v", err)
+	}
+	if len(config.SizePresets) != 2 || config.SizePresets[1].Ratio != "16:9" || config.SizePresets[1].Anchor != "top_left" {
+		t.Errorf("SizePresets = %+v", config.SizePresets)
+	}
+}
+
+func TestReplaceKeybindings(t *testing.T) {
+	src := `# my settings
+drag:
+    enabled: true # opted in
+keybindings:
+    - modifier: [Ctrl, Alt]
+      key: L
+      bindfeature: moveToLeft
+`
+	kbs := []KeyBinding{{Modifier: []string{"Ctrl", "Alt"}, Key: "R", BindFeature: "moveToRight"}}
+	data, err := replaceKeybindings([]byte(src), kbs)
+	if err != nil {
+		t.Fatalf("replaceKeybindings: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{"# my settings", "# opted in", "moveToRight"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"moveToLeft", "snap_distance", "leader", "resize"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output has %q:\n%s", unwanted, out)
+		}
+	}
+	var config Configuration
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v\n%s", err, out)
+	}
+	if !config.Drag.Enabled || len(config.Keybindings) != 1 || config.Keybindings[0].BindFeature != "moveToRight" {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	for _, src := range []string{"", "drag:\n    enabled: true\n"} {
+		data, err := replaceKeybindings([]byte(src), kbs)
+		if err != nil {
+			t.Fatalf("replaceKeybindings(%q): %v", src, err)
+		}
+		var config Configuration
+		if err := yaml.Unmarshal(data, &config); err != nil || len(config.Keybindings) != 1 {
+			t.Errorf("replaceKeybindings(%q) = %s, want the keybinding added", src, data)
+		}
+	}
+	if _, err := replaceKeybindings([]byte("- a\n- b\n"), kbs); err == nil {
+		t.Error("replaceKeybindings of a list should fail")
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/gonutz/w32/v2"
+)
+
+// Hotkeys that can't be used: the shortcuts Windows reserves, and, found
+// by registering them, the ones other programs hold. The settings window
+// checks new hotkeys against both and suggests free ones instead.
+
+type reservedHotkey struct {
+	mod, vk int32
+	// what Windows uses the hotkey for
+	use string
+}
+
+// reservedHotkeys are the Windows shortcuts that RegisterHotKey refuses or
+// that would stop working if a hotkey took them.
+var reservedHotkeys = []reservedHotkey{
+	{MOD_WIN, 'L', "locking the PC"},
+	{MOD_WIN, 'D', "showing the desktop"},
+	{MOD_WIN, 'E', "opening File Explorer"},
+	{MOD_WIN, 'R', "the Run dialog"},
+	{MOD_WIN, 'I', "opening Settings"},
+	{MOD_WIN, 'A', "Quick Settings"},
+	{MOD_WIN, 'S', "Search"},
+	{MOD_WIN, 'X', "the Quick Link menu"},
+	{MOD_WIN, 'V', "clipboard history"},
+	{MOD_WIN, 'P', "projecting to another screen"},
+	{MOD_WIN, 'K', "Cast"},
+	{MOD_WIN, 'G', "the Game Bar"},
+	{MOD_WIN, 'H', "voice typing"},
+	{MOD_WIN, 'N', "notifications"},
+	{MOD_WIN, 'W', "Widgets"},
+	{MOD_WIN, 'U', "Accessibility settings"},
+	{MOD_WIN, 'M', "minimizing all windows"},
+	{MOD_WIN | MOD_SHIFT, 'M', "restoring minimized windows"},
+	{MOD_WIN | MOD_SHIFT, 'S', "taking a screenshot"},
+	{MOD_WIN, w32.VK_SNAPSHOT, "saving a screenshot"},
+	{MOD_WIN, w32.VK_OEM_PERIOD, "the emoji panel"},
+	{MOD_WIN, w32.VK_TAB, "Task View"},
+	{MOD_WIN, w32.VK_SPACE, "switching the input language"},
+	{MOD_WIN, w32.VK_LEFT, "snapping windows"},
+	{MOD_WIN, w32.VK_RIGHT, "snapping windows"},
+	{MOD_WIN, w32.VK_UP, "maximizing the window"},
+	{MOD_WIN, w32.VK_DOWN, "minimizing the window"},
+	{MOD_WIN | MOD_SHIFT, w32.VK_LEFT, "moving the window to another monitor"},
+	{MOD_WIN | MOD_SHIFT, w32.VK_RIGHT, "moving the window to another monitor"},
+	{MOD_WIN | MOD_CONTROL, 'D', "adding a virtual desktop"},
+	{MOD_WIN | MOD_CONTROL, w32.VK_F4, "closing the virtual desktop"},
+	{MOD_WIN | MOD_CONTROL, w32.VK_LEFT, "switching virtual desktops"},
+	{MOD_WIN | MOD_CONTROL, w32.VK_RIGHT, "switching virtual desktops"},
+	{MOD_CONTROL | MOD_ALT, w32.VK_DELETE, "the security screen"},
+	{MOD_CONTROL | MOD_SHIFT, w32.VK_ESCAPE, "Task Manager"},
+	{MOD_CONTROL, w32.VK_ESCAPE, "the Start menu"},
+	{MOD_ALT, w32.VK_TAB, "switching windows"},
+	{MOD_ALT, w32.VK_F4, "closing the window"},
+}
+
+// reservedHotkeyUse reports what Windows uses mod+vk for, if it is
+// reserved.
+func reservedHotkeyUse(mod, vk int32) (string, bool) {
+	mod &^= MOD_NOREPEAT
+	for _, r := range reservedHotkeys {
+		if r.mod == mod && r.vk == vk {
+			return r.use, true
+		}
+	}
+	return "", false
+}
+
+// suggestionMods are the modifiers tried for alternatives, most usual
+// first.
+var suggestionMods = []int32{
+	MOD_CONTROL | MOD_ALT,
+	MOD_CONTROL | MOD_ALT | MOD_SHIFT,
+	MOD_WIN | MOD_ALT,
+	MOD_CONTROL | MOD_WIN | MOD_ALT,
+	MOD_WIN | MOD_ALT | MOD_SHIFT,
+}
+
+// suggestionKeys are the keys tried for alternatives with the same
+// modifiers.
+const suggestionKeys = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// suggestHotkeys suggests up to n hotkeys instead of mod+vk that aren't
+// reserved and that free reports free: first vk with other modifiers,
+// then mod with the letters and digits after vk.
+func suggestHotkeys(mod, vk int32, free func(mod, vk int32) bool, n int) []HotKey {
+	mod &^= MOD_NOREPEAT
+	var out []HotKey
+	try := func(m, k int32) bool {
+		if m == mod && k == vk {
+			return false
+		}
+		if _, reserved := reservedHotkeyUse(m, k); reserved || !free(m, k) {
+			return false
+		}
+		out = append(out, HotKey{mod: int(m), vk: int(k)})
+		return len(out) >= n
+	}
+	if n <= 0 {
+		return nil
+	}
+	for _, m := range suggestionMods {
+		if try(m, vk) {
+			return out
+		}
+	}
+	start := strings.IndexRune(suggestionKeys, rune(vk))
+	for i := 1; i <= len(suggestionKeys); i++ {
+		if try(mod, int32(suggestionKeys[(start+i)%len(suggestionKeys)])) {
+			return out
+		}
+	}
+	return out
+}
+
+// describeSuggestions is the sentence offering hks as alternatives, or ""
+// if there are none.
+func describeSuggestions(hks []HotKey) string {
+	if len(hks) == 0 {
+		return ""
+	}
+	var descs []string
+	for _, hk := range hks {
+		descs = append(descs, hk.ShortDescribe())
+	}
+	return "Free alternatives: " + strings.Join(descs, ", ") + "."
+}
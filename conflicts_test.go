@@ -0,0 +1,111 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestReservedHotkeyUse(t *testing.T) {
+	tests := []struct {
+		mod, vk  int32
+		reserved bool
+	}{
+		{MOD_WIN, 'L', true},
+		{MOD_WIN | MOD_NOREPEAT, 'D', true},
+		{MOD_ALT, 0x09, true},                 // Alt+Tab
+		{MOD_CONTROL | MOD_SHIFT, 0x1B, true}, // Ctrl+Shift+Esc
+		{MOD_WIN | MOD_ALT, 'L', false},
+		{MOD_CONTROL | MOD_ALT, 'D', false},
+		{MOD_WIN | MOD_CONTROL | MOD_ALT, 0x25, false},
+	}
+	for _, tt := range tests {
+		if _, got := reservedHotkeyUse(tt.mod, tt.vk); got != tt.reserved {
+			t.Errorf("reservedHotkeyUse(0x%x, 0x%x) = %v, want %v", tt.mod, tt.vk, got, tt.reserved)
+		}
+	}
+	for i, a := range reservedHotkeys {
+		for _, b := range reservedHotkeys[i+1:] {
+			if a.mod == b.mod && a.vk == b.vk {
+				t.Errorf("reserved hotkey 0x%x+0x%x listed twice", a.mod, a.vk)
+			}
+		}
+	}
+}
+
+func TestExampleConfigAvoidsReservedHotkeys(t *testing.T) {
+	var conf Configuration
+	if err := yaml.Unmarshal(configExampleYaml, &conf); err != nil {
+		t.Fatal(err)
+	}
+	conf = parseConfiguration(conf)
+	for _, kb := range conf.Keybindings {
+		if use, ok := reservedHotkeyUse(kb.CombinedMod, kb.KeyCode); ok {
+			t.Errorf("%v+%s for %s is reserved by Windows for %s", kb.Modifier, kb.Key, kb.BindFeature, use)
+		}
+	}
+}
+
+func TestSuggestHotkeys(t *testing.T) {
+	all := func(mod, vk int32) bool { return true }
+	describe := func(hks []HotKey) []string {
+		var out []string
+		for _, hk := range hks {
+			out = append(out, hk.ShortDescribe())
+		}
+		return out
+	}
+
+	// the same key with other modifiers comes first
+	got := describe(suggestHotkeys(MOD_WIN, 'L', all, 3))
+	want := []string{"Ctrl + Alt + L", "Ctrl + Alt + Shift + L", "Win + Alt + L"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestHotkeys(Win+L) = %v, want %v", got, want)
+	}
+
+	// the original hotkey is never suggested, nor are taken ones
+	taken := func(mod, vk int32) bool { return vk != 'Q' }
+	got = describe(suggestHotkeys(MOD_CONTROL|MOD_ALT, 'Q', taken, 2))
+	want = []string{"Ctrl + Alt + R", "Ctrl + Alt + S"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestHotkeys(Ctrl+Alt+Q) = %v, want %v", got, want)
+	}
+
+	// reserved hotkeys are skipped, and the keys wrap around after 9
+	onlyWin := func(mod, vk int32) bool { return mod == MOD_WIN }
+	got = describe(suggestHotkeys(MOD_WIN, '9', onlyWin, 4))
+	want = []string{"Win + B", "Win + C", "Win + F", "Win + J"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestHotkeys(Win+9) = %v, want %v", got, want)
+	}
+
+	none := func(mod, vk int32) bool { return false }
+	if got := suggestHotkeys(MOD_WIN, 'L', none, 3); len(got) != 0 {
+		t.Errorf("suggestHotkeys with nothing free = %v, want none", describe(got))
+	}
+}
+
+func TestDescribeSuggestions(t *testing.T) {
+	hks := []HotKey{{mod: MOD_CONTROL | MOD_ALT, vk: 'L'}, {mod: MOD_WIN | MOD_ALT, vk: 'L'}}
+	if got, want := describeSuggestions(hks), "Free alternatives: Ctrl + Alt + L, Win + Alt + L."; got != want {
+		t.Errorf("describeSuggestions = %q, want %q", got, want)
+	}
+	if got := describeSuggestions(nil); got != "" {
+		t.Errorf("describeSuggestions(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gonutz/w32/v2"
+)
+
+// Virtual desktops: moving windows between them, and keying per-desktop
+// state. Windows access goes through the virtualDesktops interface,
+// implemented in desktop_com.go.
+
+// desktopID identifies a virtual desktop by the bytes of its GUID. The
+// zero desktopID stands for "any desktop".
+type desktopID [16]byte
+
+func (id desktopID) String() string {
+	return fmt.Sprintf("%x", id[:])
+}
+
+type virtualDesktops interface {
+	// Desktops lists the desktops in the order of Task View.
+	Desktops() ([]desktopID, error)
+	// Current returns the desktop on screen.
+	Current() (desktopID, error)
+	WindowDesktop(hwnd w32.HWND) (desktopID, error)
+	MoveWindow(hwnd w32.HWND, id desktopID) error
+}
+
+// desktops is the system's virtual desktops, set up in main. It stays nil
+// where they are not available.
+var desktops virtualDesktops
+
+var errNoDesktops = errors.New("virtual desktops are not available")
+
+func desktopIndex(list []desktopID, id desktopID) int {
+	for i, d := range list {
+		if d == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// moveToDesktop moves hwnd to the desktop at the 0-based index.
+func moveToDesktop(vd virtualDesktops, hwnd w32.HWND, index int) error {
+	if vd == nil {
+		return errNoDesktops
+	}
+	list, err := vd.Desktops()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(list) {
+		return fmt.Errorf("no desktop %d, there are %d", index+1, len(list))
+	}
+	return vd.MoveWindow(hwnd, list[index])
+}
+
+// moveByDesktops moves hwnd delta desktops along from its own, stopping
+// at the first and last desktop like Ctrl+Win+Left and Right do.
+func moveByDesktops(vd virtualDesktops, hwnd w32.HWND, delta int) error {
+	if vd == nil {
+		return errNoDesktops
+	}
+	list, err := vd.Desktops()
+	if err != nil {
+		return err
+	}
+	cur, err := vd.WindowDesktop(hwnd)
+	if err != nil {
+		return err
+	}
+	i := desktopIndex(list, cur)
+	if i < 0 {
+		return fmt.Errorf("window is on unknown desktop %v", cur)
+	}
+	j := i + delta
+	if j < 0 || j >= len(list) {
+		return fmt.Errorf("no desktop %d, there are %d", j+1, len(list))
+	}
+	if j == i {
+		return nil
+	}
+	return vd.MoveWindow(hwnd, list[j])
+}
+
+// desktopScope returns the key to keep per-desktop state such as the undo
+// history under: the current desktop if perDesktop is set, else the zero
+// desktopID shared by all desktops. It falls back to the shared key when
+// the current desktop is unknown.
+func desktopScope(vd virtualDesktops, perDesktop bool) desktopID {
+	if !perDesktop || vd == nil {
+		return desktopID{}
+	}
+	id, err := vd.Current()
+	if err != nil {
+		fmt.Printf("warn: current desktop: %v\n", err)
+		return desktopID{}
+	}
+	return id
+}
+
+func desktopFeature(delta int) func() {
+	return func() {
+		if err := moveByDesktops(desktops, getTargetWindow(), delta); err != nil {
+			fmt.Printf("warn: move to desktop: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// windowsDesktops implements virtualDesktops with the documented
+// IVirtualDesktopManager COM interface, and the registry for the list of
+// desktops which that interface does not offer.
+
+const (
+	COINIT_APARTMENTTHREADED = 0x2
+	CLSCTX_ALL               = 0x17
+	RPC_E_CHANGED_MODE       = 0x80010106
+	E_ACCESSDENIED           = 0x80070005
+)
+
+const virtualDesktopsKey = `Software\Microsoft\Windows\CurrentVersion\Explorer\VirtualDesktops`
+
+var (
+	ole32                = windows.NewLazySystemDLL("ole32.dll")
+	procCoInitializeEx   = ole32.NewProc("CoInitializeEx")
+	procCoUninitialize   = ole32.NewProc("CoUninitialize")
+	procCoCreateInstance = ole32.NewProc("CoCreateInstance")
+
+	clsidVirtualDesktopManager = windows.GUID{Data1: 0xAA509086, Data2: 0x5CA9, Data3: 0x4C25,
+		Data4: [8]byte{0x8F, 0x95, 0x58, 0x9D, 0x3C, 0x07, 0xB4, 0x8A}}
+	iidIVirtualDesktopManager = windows.GUID{Data1: 0xA5CD92FF, Data2: 0x29BE, Data3: 0x454C,
+		Data4: [8]byte{0x8D, 0x04, 0xD8, 0x28, 0x79, 0xFB, 0x3F, 0x1B}}
+)
+
+type iVirtualDesktopManagerVtbl struct {
+	QueryInterface                  uintptr
+	AddRef                          uintptr
+	Release                         uintptr
+	IsWindowOnCurrentVirtualDesktop uintptr
+	GetWindowDesktopId              uintptr
+	MoveWindowToDesktop             uintptr
+}
+
+type iVirtualDesktopManager struct {
+	vtbl *iVirtualDesktopManagerVtbl
+}
+
+type windowsDesktops struct{}
+
+// withManager calls f with an IVirtualDesktopManager, initializing COM on
+// the calling thread for the duration.
+func withManager(f func(m *iVirtualDesktopManager) error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	hr, _, _ := procCoInitializeEx.Call(0, COINIT_APARTMENTTHREADED)
+	if int32(hr) >= 0 {
+		defer procCoUninitialize.Call()
+	} else if uint32(hr) != RPC_E_CHANGED_MODE {
+		return fmt.Errorf("CoInitializeEx: 0x%08X", uint32(hr))
+	}
+	var m *iVirtualDesktopManager
+	hr, _, _ = procCoCreateInstance.Call(
+		uintptr(unsafe.Pointer(&clsidVirtualDesktopManager)),
+		0,
+		CLSCTX_ALL,
+		uintptr(unsafe.Pointer(&iidIVirtualDesktopManager)),
+		uintptr(unsafe.Pointer(&m)))
+	if int32(hr) < 0 || m == nil {
+		return fmt.Errorf("CoCreateInstance(VirtualDesktopManager): 0x%08X", uint32(hr))
+	}
+	defer syscall.Syscall(m.vtbl.Release, 1, uintptr(unsafe.Pointer(m)), 0, 0)
+	return f(m)
+}
+
+func (windowsDesktops) WindowDesktop(hwnd w32.HWND) (desktopID, error) {
+	var guid windows.GUID
+	err := withManager(func(m *iVirtualDesktopManager) error {
+		hr, _, _ := syscall.Syscall(m.vtbl.GetWindowDesktopId, 3,
+			uintptr(unsafe.Pointer(m)), uintptr(hwnd), uintptr(unsafe.Pointer(&guid)))
+		if int32(hr) < 0 {
+			return fmt.Errorf("GetWindowDesktopId: 0x%08X", uint32(hr))
+		}
+		return nil
+	})
+	return guidToDesktopID(guid), err
+}
+
+func (windowsDesktops) MoveWindow(hwnd w32.HWND, id desktopID) error {
+	guid := desktopIDToGUID(id)
+	return withManager(func(m *iVirtualDesktopManager) error {
+		hr, _, _ := syscall.Syscall(m.vtbl.MoveWindowToDesktop, 3,
+			uintptr(unsafe.Pointer(m)), uintptr(hwnd), uintptr(unsafe.Pointer(&guid)))
+		if uint32(hr) == E_ACCESSDENIED {
+			return fmt.Errorf("Windows refused to move the window of another process to desktop %v", id)
+		}
+		if int32(hr) < 0 {
+			return fmt.Errorf("MoveWindowToDesktop: 0x%08X", uint32(hr))
+		}
+		return nil
+	})
+}
+
+// Desktops reads the desktop list Explorer keeps in the registry. It is
+// missing until a second desktop is created, in which case the current
+// desktop is the only one.
+func (d windowsDesktops) Desktops() ([]desktopID, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, virtualDesktopsKey, registry.QUERY_VALUE)
+	if err == nil {
+		defer k.Close()
+		if data, _, err := k.GetBinaryValue("VirtualDesktopIDs"); err == nil && len(data) >= 16 {
+			var list []desktopID
+			for i := 0; i+16 <= len(data); i += 16 {
+				var id desktopID
+				copy(id[:], data[i:i+16])
+				list = append(list, id)
+			}
+			return list, nil
+		}
+	}
+	cur, err := d.Current()
+	if err != nil {
+		return nil, err
+	}
+	return []desktopID{cur}, nil
+}
+
+// Current reads the current desktop from the registry: Windows 11 keeps it
+// next to the desktop list, Windows 10 under the logon session.
+func (windowsDesktops) Current() (desktopID, error) {
+	var session uint32
+	keys := []string{virtualDesktopsKey}
+	if windows.ProcessIdToSessionId(windows.GetCurrentProcessId(), &session) == nil {
+		keys = append(keys, fmt.Sprintf(`Software\Microsoft\Windows\CurrentVersion\Explorer\SessionInfo\%d\VirtualDesktops`, session))
+	}
+	for _, key := range keys {
+		k, err := registry.OpenKey(registry.CURRENT_USER, key, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		data, _, err := k.GetBinaryValue("CurrentVirtualDesktop")
+		k.Close()
+		if err == nil && len(data) == 16 {
+			var id desktopID
+			copy(id[:], data)
+			return id, nil
+		}
+	}
+	return desktopID{}, errNoDesktops
+}
+
+// The registry holds GUIDs in their in-memory layout, with the first three
+// fields little-endian.
+func guidToDesktopID(g windows.GUID) desktopID {
+	var id desktopID
+	binary.LittleEndian.PutUint32(id[0:], g.Data1)
+	binary.LittleEndian.PutUint16(id[4:], g.Data2)
+	binary.LittleEndian.PutUint16(id[6:], g.Data3)
+	copy(id[8:], g.Data4[:])
+	return id
+}
+
+func desktopIDToGUID(id desktopID) windows.GUID {
+	g := windows.GUID{
+		Data1: binary.LittleEndian.Uint32(id[0:]),
+		Data2: binary.LittleEndian.Uint16(id[4:]),
+		Data3: binary.LittleEndian.Uint16(id[6:]),
+	}
+	copy(g.Data4[:], id[8:])
+	return g
+}
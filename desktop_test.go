@@ -0,0 +1,147 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// fakeDesktops keeps desktops and the windows on them in memory.
+type fakeDesktops struct {
+	list    []desktopID
+	current desktopID
+	windows map[w32.HWND]desktopID
+	err     error
+}
+
+func newFakeDesktops(n int) *fakeDesktops {
+	f := &fakeDesktops{windows: map[w32.HWND]desktopID{}}
+	for i := 1; i <= n; i++ {
+		f.list = append(f.list, desktopID{byte(i)})
+	}
+	f.current = f.list[0]
+	return f
+}
+
+func (f *fakeDesktops) Desktops() ([]desktopID, error) { return f.list, f.err }
+func (f *fakeDesktops) Current() (desktopID, error)    { return f.current, f.err }
+
+func (f *fakeDesktops) WindowDesktop(hwnd w32.HWND) (desktopID, error) {
+	id, ok := f.windows[hwnd]
+	if !ok {
+		return desktopID{}, errors.New("no such window")
+	}
+	return id, f.err
+}
+
+func (f *fakeDesktops) MoveWindow(hwnd w32.HWND, id desktopID) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.windows[hwnd] = id
+	return nil
+}
+
+func TestMoveByDesktops(t *testing.T) {
+	vd := newFakeDesktops(3)
+	vd.windows[1] = vd.list[0]
+
+	if err := moveByDesktops(vd, 1, 1); err != nil || vd.windows[1] != vd.list[1] {
+		t.Errorf("next from desktop 1: err %v, on %v", err, vd.windows[1])
+	}
+	if err := moveByDesktops(vd, 1, 1); err != nil || vd.windows[1] != vd.list[2] {
+		t.Errorf("next from desktop 2: err %v, on %v", err, vd.windows[1])
+	}
+	// no wrapping past the last desktop
+	if err := moveByDesktops(vd, 1, 1); err == nil || vd.windows[1] != vd.list[2] {
+		t.Errorf("next from the last desktop: err %v, on %v", err, vd.windows[1])
+	}
+	if err := moveByDesktops(vd, 1, -2); err != nil || vd.windows[1] != vd.list[0] {
+		t.Errorf("two back from desktop 3: err %v, on %v", err, vd.windows[1])
+	}
+	if err := moveByDesktops(vd, 1, -1); err == nil {
+		t.Error("previous from the first desktop should fail")
+	}
+
+	vd.windows[2] = desktopID{99}
+	if err := moveByDesktops(vd, 2, 1); err == nil {
+		t.Error("window on an unknown desktop should fail")
+	}
+	if err := moveByDesktops(vd, 3, 1); err == nil {
+		t.Error("unknown window should fail")
+	}
+	if err := moveByDesktops(nil, 1, 1); err != errNoDesktops {
+		t.Errorf("without desktops: err %v, want %v", err, errNoDesktops)
+	}
+}
+
+func TestMoveToDesktop(t *testing.T) {
+	vd := newFakeDesktops(4)
+	vd.windows[1] = vd.list[0]
+	for _, i := range []int{3, 1, 0} {
+		if err := moveToDesktop(vd, 1, i); err != nil || vd.windows[1] != vd.list[i] {
+			t.Errorf("moveToDesktop(%d): err %v, on %v", i, err, vd.windows[1])
+		}
+	}
+	for _, i := range []int{-1, 4} {
+		if err := moveToDesktop(vd, 1, i); err == nil {
+			t.Errorf("moveToDesktop(%d) should fail", i)
+		}
+	}
+	vd.err = errors.New("broken")
+	if err := moveToDesktop(vd, 1, 2); err != vd.err {
+		t.Errorf("moveToDesktop with a failing API: err %v", err)
+	}
+}
+
+func TestDesktopScope(t *testing.T) {
+	vd := newFakeDesktops(2)
+	vd.current = vd.list[1]
+	if got := desktopScope(vd, true); got != vd.list[1] {
+		t.Errorf("per desktop scope = %v, want %v", got, vd.list[1])
+	}
+	if got := desktopScope(vd, false); got != (desktopID{}) {
+		t.Errorf("shared scope = %v, want zero", got)
+	}
+	if got := desktopScope(nil, true); got != (desktopID{}) {
+		t.Errorf("scope without desktops = %v, want zero", got)
+	}
+	vd.err = errors.New("broken")
+	if got := desktopScope(vd, true); got != (desktopID{}) {
+		t.Errorf("scope with a failing API = %v, want zero", got)
+	}
+}
+
+func TestNewMoveToDesktopFeature(t *testing.T) {
+	for _, args := range []string{"0", "-2", "next", "{n: 1}"} {
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(args), &node); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newMoveToDesktopFeature(node.Content[0]); err == nil {
+			t.Errorf("newMoveToDesktopFeature(%s) expected error", args)
+		}
+	}
+	if _, err := newMoveToDesktopFeature(nil); err == nil {
+		t.Error("newMoveToDesktopFeature(nil) expected error")
+	}
+	if _, err := newMoveToDesktopFeature(yamlNode(t, "3")); err != nil {
+		t.Errorf("newMoveToDesktopFeature(3): %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+
+	"github.com/gonutz/w32/v2"
+)
+
+// Finding windows by geometry, for the features that act on a window and
+// its neighbor.
+
+type direction int
+
+const (
+	dirLeft direction = iota
+	dirRight
+	dirUp
+	dirDown
+)
+
+func rectCenter(r w32.RECT) (x, y int32) {
+	return (r.Left + r.Right) / 2, (r.Top + r.Bottom) / 2
+}
+
+// nearestInDirection returns the index of the candidate closest to from in
+// direction d, or -1 if there is none. A candidate counts if its center
+// lies beyond the center of from in that direction. Candidates that overlap
+// from on the other axis (e.g. the window right next to it, rather than
+// one diagonally away) win over those that don't; after that the closest
+// center wins, with offsets across the direction counting double.
+func nearestInDirection(from w32.RECT, candidates []w32.RECT, d direction) int {
+	fx, fy := rectCenter(from)
+	best, bestOverlap, bestScore := -1, false, int64(0)
+	for i, c := range candidates {
+		cx, cy := rectCenter(c)
+		var along, across int32
+		var overlap bool
+		switch d {
+		case dirLeft:
+			along, across = fx-cx, cy-fy
+			overlap = c.Top < from.Bottom && from.Top < c.Bottom
+		case dirRight:
+			along, across = cx-fx, cy-fy
+			overlap = c.Top < from.Bottom && from.Top < c.Bottom
+		case dirUp:
+			along, across = fy-cy, cx-fx
+			overlap = c.Left < from.Right && from.Left < c.Right
+		case dirDown:
+			along, across = cy-fy, cx-fx
+			overlap = c.Left < from.Right && from.Left < c.Right
+		}
+		if along <= 0 {
+			continue
+		}
+		if across < 0 {
+			across = -across
+		}
+		score := int64(along) + 2*int64(across)
+		if best < 0 || overlap && !bestOverlap || overlap == bestOverlap && score < bestScore {
+			best, bestOverlap, bestScore = i, overlap, score
+		}
+	}
+	return best
+}
+
+// nextInReadingOrder returns the index of the candidate that follows from
+// when windows are ordered left to right, then top to bottom, wrapping
+// around to the first one. It returns -1 if there are no candidates.
+func nextInReadingOrder(from w32.RECT, candidates []w32.RECT) int {
+	if len(candidates) == 0 {
+		return -1
+	}
+	less := func(a, b w32.RECT) bool {
+		if a.Left != b.Left {
+			return a.Left < b.Left
+		}
+		if a.Top != b.Top {
+			return a.Top < b.Top
+		}
+		// identical origins: the larger window comes first
+		return a.Width()*a.Height() > b.Width()*b.Height()
+	}
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return less(candidates[order[i]], candidates[order[j]]) })
+	for _, i := range order {
+		if less(from, candidates[i]) {
+			return i
+		}
+	}
+	return order[0]
+}
+
+// subtractRect returns the parts of r not covered by cut, as up to four
+// non-overlapping rects.
+func subtractRect(r, cut w32.RECT) []w32.RECT {
+	if cut.Left >= r.Right || cut.Right <= r.Left || cut.Top >= r.Bottom || cut.Bottom <= r.Top {
+		return []w32.RECT{r}
+	}
+	var parts []w32.RECT
+	if cut.Top > r.Top {
+		parts = append(parts, w32.RECT{Left: r.Left, Top: r.Top, Right: r.Right, Bottom: cut.Top})
+	}
+	if cut.Bottom < r.Bottom {
+		parts = append(parts, w32.RECT{Left: r.Left, Top: cut.Bottom, Right: r.Right, Bottom: r.Bottom})
+	}
+	top, bottom := max(r.Top, cut.Top), min(r.Bottom, cut.Bottom)
+	if cut.Left > r.Left {
+		parts = append(parts, w32.RECT{Left: r.Left, Top: top, Right: cut.Left, Bottom: bottom})
+	}
+	if cut.Right < r.Right {
+		parts = append(parts, w32.RECT{Left: cut.Right, Top: top, Right: r.Right, Bottom: bottom})
+	}
+	return parts
+}
+
+// isCovered reports whether the rects in above cover r completely.
+func isCovered(r w32.RECT, above []w32.RECT) bool {
+	visible := []w32.RECT{r}
+	for _, cut := range above {
+		var next []w32.RECT
+		for _, v := range visible {
+			next = append(next, subtractRect(v, cut)...)
+		}
+		visible = next
+		if len(visible) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// uncoveredWindows takes window frames in z-order, topmost first, and
+// returns the indices of those not completely hidden by the ones above.
+func uncoveredWindows(frames []w32.RECT) []int {
+	var result []int
+	for i, r := range frames {
+		if !isCovered(r, frames[:i]) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
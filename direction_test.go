@@ -0,0 +1,185 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestNearestInDirection(t *testing.T) {
+	// a 3x2 grid of 100x100 windows:
+	//   0 1 2
+	//   3 4 5
+	grid := []w32.RECT{
+		rect(0, 0, 100, 100), rect(100, 0, 200, 100), rect(200, 0, 300, 100),
+		rect(0, 100, 100, 200), rect(100, 100, 200, 200), rect(200, 100, 300, 200),
+	}
+	cases := []struct {
+		name string
+		from int
+		d    direction
+		want int
+	}{
+		{"right of 0", 0, dirRight, 1},
+		{"right of 1", 1, dirRight, 2},
+		{"right of 2", 2, dirRight, -1},
+		{"left of 5", 5, dirLeft, 4},
+		{"left of 3", 3, dirLeft, -1},
+		{"down from 1", 1, dirDown, 4},
+		{"up from 5", 5, dirUp, 2},
+		{"up from 0", 0, dirUp, -1},
+	}
+	for _, c := range cases {
+		var others []w32.RECT
+		var index []int
+		for i, r := range grid {
+			if i != c.from {
+				others = append(others, r)
+				index = append(index, i)
+			}
+		}
+		got := nearestInDirection(grid[c.from], others, c.d)
+		if got >= 0 {
+			got = index[got]
+		}
+		if got != c.want {
+			t.Errorf("%s: got %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestNearestInDirectionPrefersOverlap(t *testing.T) {
+	from := rect(0, 400, 500, 600)
+	candidates := []w32.RECT{
+		rect(520, 0, 700, 300),     // close, but diagonally up-right
+		rect(1500, 350, 1900, 650), // far, but level with from
+	}
+	if got := nearestInDirection(from, candidates, dirRight); got != 1 {
+		t.Errorf("got %d, want the level window 1", got)
+	}
+	// without an overlapping candidate the diagonal one is used
+	if got := nearestInDirection(from, candidates[:1], dirRight); got != 0 {
+		t.Errorf("got %d, want the diagonal window 0", got)
+	}
+}
+
+func TestNearestInDirectionHalves(t *testing.T) {
+	left := rect(0, 0, 960, 1040)
+	right := rect(960, 0, 1920, 1040)
+	if got := nearestInDirection(left, []w32.RECT{right}, dirRight); got != 0 {
+		t.Errorf("right half not found from left half: %d", got)
+	}
+	if got := nearestInDirection(left, []w32.RECT{right}, dirLeft); got != -1 {
+		t.Errorf("left of the left half = %d, want -1", got)
+	}
+	// overlapping windows still have a direction by their centers
+	big := rect(0, 0, 1000, 1000)
+	small := rect(700, 400, 900, 600)
+	if got := nearestInDirection(big, []w32.RECT{small}, dirRight); got != 0 {
+		t.Errorf("overlapping window to the right not found: %d", got)
+	}
+}
+
+func TestNearestInDirectionAcrossMonitors(t *testing.T) {
+	// a window on the right edge of the primary monitor, and windows on a
+	// second monitor to its right and a third one to its left
+	from := rect(1400, 0, 1920, 1040)
+	candidates := []w32.RECT{
+		rect(1920, 0, 2880, 1040),
+		rect(-1280, 0, 0, 1024),
+	}
+	if got := nearestInDirection(from, candidates, dirRight); got != 0 {
+		t.Errorf("right = %d, want 0", got)
+	}
+	if got := nearestInDirection(from, candidates, dirLeft); got != 1 {
+		t.Errorf("left = %d, want 1", got)
+	}
+}
+
+func TestNextInReadingOrder(t *testing.T) {
+	a := rect(0, 0, 100, 100)
+	b := rect(0, 100, 100, 200)
+	c := rect(100, 0, 200, 200)
+	if got := nextInReadingOrder(a, []w32.RECT{c, b}); got != 1 {
+		t.Errorf("after a = %d, want b (1)", got)
+	}
+	if got := nextInReadingOrder(b, []w32.RECT{a, c}); got != 1 {
+		t.Errorf("after b = %d, want c (1)", got)
+	}
+	if got := nextInReadingOrder(c, []w32.RECT{b, a}); got != 1 {
+		t.Errorf("after c = %d, want a (1) by wrapping", got)
+	}
+	if got := nextInReadingOrder(a, nil); got != -1 {
+		t.Errorf("no candidates = %d, want -1", got)
+	}
+}
+
+func TestSubtractRect(t *testing.T) {
+	r := rect(0, 0, 100, 100)
+	cases := []struct {
+		name string
+		cut  w32.RECT
+		area int32
+		n    int
+	}{
+		{"disjoint", rect(200, 200, 300, 300), 10000, 1},
+		{"touching edge", rect(100, 0, 200, 100), 10000, 1},
+		{"whole", rect(-10, -10, 110, 110), 0, 0},
+		{"left half", rect(-10, -10, 50, 110), 5000, 1},
+		{"hole in the middle", rect(25, 25, 75, 75), 7500, 4},
+		{"corner", rect(50, 50, 150, 150), 7500, 2},
+	}
+	for _, c := range cases {
+		parts := subtractRect(r, c.cut)
+		var area int32
+		for i, p := range parts {
+			area += p.Width() * p.Height()
+			if overlaps(p, c.cut) {
+				t.Errorf("%s: part %v overlaps the cut", c.name, p)
+			}
+			for _, q := range parts[:i] {
+				if overlaps(p, q) {
+					t.Errorf("%s: parts %v and %v overlap", c.name, p, q)
+				}
+			}
+		}
+		if area != c.area || len(parts) != c.n {
+			t.Errorf("%s: got %d parts covering %d px, want %d parts covering %d px", c.name, len(parts), area, c.n, c.area)
+		}
+	}
+}
+
+func TestUncoveredWindows(t *testing.T) {
+	frames := []w32.RECT{
+		rect(0, 0, 960, 1040),      // 0: left half, on top
+		rect(960, 0, 1920, 1040),   // 1: right half
+		rect(100, 100, 500, 500),   // 2: hidden behind 0
+		rect(800, 100, 1200, 500),  // 3: hidden behind 0 and 1 together
+		rect(800, 100, 1200, 1100), // 4: sticks out below both halves
+		rect(0, 0, 1920, 1040),     // 5: maximized-size window at the bottom
+	}
+	want := []int{0, 1, 4}
+	got := uncoveredWindows(frames)
+	if len(got) != len(want) {
+		t.Fatalf("uncoveredWindows = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("uncoveredWindows = %v, want %v", got, want)
+		}
+	}
+}
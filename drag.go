@@ -0,0 +1,215 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Modifier+drag. Holding the configured modifier and dragging anywhere in a
+// window moves it; dragging with the resize button resizes it from the
+// corner nearest to the cursor. The events come from the low-level mouse
+// hook in mouse.go.
+
+type dragState struct {
+	hwnd   w32.HWND
+	button int
+	resize bool
+	// cursor position and window rect when the drag started
+	startX, startY int32
+	start          w32.RECT
+	// invisible border widths, per side, so snapping uses the visible frame
+	borders w32.RECT
+	// grabbed corner, see dragCorner
+	sx, sy int32
+}
+
+var (
+	dragConfig DragConfig
+	dragging   *dragState
+)
+
+// dragCorner picks the corner of r nearest to (x, y), as a sign per axis:
+// -1 for the left or top edge, +1 for the right or bottom edge.
+func dragCorner(r w32.RECT, x, y int32) (sx, sy int32) {
+	sx, sy = -1, -1
+	if 2*x >= r.Left+r.Right {
+		sx = 1
+	}
+	if 2*y >= r.Top+r.Bottom {
+		sy = 1
+	}
+	return sx, sy
+}
+
+// dragResizeRect moves the edges of the grabbed corner by (dx, dy), keeping
+// the opposite edges in place and the window at least minSize on each axis.
+func dragResizeRect(start w32.RECT, sx, sy, dx, dy, minSize int32) w32.RECT {
+	r := start
+	if sx < 0 {
+		r.Left = min(start.Left+dx, start.Right-minSize)
+	} else {
+		r.Right = max(start.Right+dx, start.Left+minSize)
+	}
+	if sy < 0 {
+		r.Top = min(start.Top+dy, start.Bottom-minSize)
+	} else {
+		r.Bottom = max(start.Bottom+dy, start.Top+minSize)
+	}
+	return r
+}
+
+func dragMoveRect(start w32.RECT, dx, dy int32) w32.RECT {
+	return w32.RECT{
+		Left:   start.Left + dx,
+		Top:    start.Top + dy,
+		Right:  start.Right + dx,
+		Bottom: start.Bottom + dy,
+	}
+}
+
+// snapToEdges shifts r so that an edge within distance of the matching
+// work-area edge lies on it. The size of r is kept.
+func snapToEdges(r, work w32.RECT, distance int32) w32.RECT {
+	abs := func(v int32) int32 {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+	var dx, dy int32
+	if d := work.Left - r.Left; abs(d) <= distance {
+		dx = d
+	} else if d := work.Right - r.Right; abs(d) <= distance {
+		dx = d
+	}
+	if d := work.Top - r.Top; abs(d) <= distance {
+		dy = d
+	} else if d := work.Bottom - r.Bottom; abs(d) <= distance {
+		dy = d
+	}
+	return dragMoveRect(r, dx, dy)
+}
+
+// setupDrag enables modifier+drag if the configuration asks for it. Like
+// RegisterHotKey, it must be called on the thread running msgLoop.
+func setupDrag(dc DragConfig) {
+	if !dc.Enabled || dc.MoveKeyCode == 0 && dc.ResizeKeyCode == 0 {
+		return
+	}
+	dragConfig = dc
+	if !acquireMouseHook() {
+		dragConfig.Enabled = false
+	}
+}
+
+// handleDragMouse is called by the mouse hook for every mouse event and
+// reports whether the event should be swallowed.
+func handleDragMouse(msg uint32, info *w32ex.MSLLHOOKSTRUCT) bool {
+	if !dragConfig.Enabled {
+		return false
+	}
+	if msg == w32.WM_MOUSEMOVE {
+		if dragging != nil {
+			dragTo(info.Pt)
+		}
+		// the cursor itself has to keep moving
+		return false
+	}
+	vk, down, ok := mouseTrigger(msg, info.MouseData)
+	if !ok {
+		return false
+	}
+	if dragging != nil {
+		// swallow clicks of the other buttons too while dragging
+		if !down && vk == dragging.button {
+			dragging = nil
+		}
+		return true
+	}
+	if !down || int32(modifierState(isKeyDown)) != dragConfig.CombinedMod {
+		return false
+	}
+	switch int32(vk) {
+	case dragConfig.MoveKeyCode:
+		return startDrag(vk, false, info.Pt)
+	case dragConfig.ResizeKeyCode:
+		return startDrag(vk, true, info.Pt)
+	}
+	return false
+}
+
+func startDrag(button int, resize bool, pt w32.POINT) bool {
+	hwnd := w32ex.GetAncestor(w32ex.WindowFromPoint(pt.X, pt.Y), w32ex.GA_ROOT)
+	if !isZonableWindow(hwnd) || w32ex.IsZoomed(hwnd) {
+		return false
+	}
+	rect := w32.GetWindowRect(hwnd)
+	if rect == nil {
+		return false
+	}
+	ok, frame := w32.DwmGetWindowAttributeEXTENDED_FRAME_BOUNDS(hwnd)
+	if !ok {
+		frame = *rect
+	}
+	d := &dragState{
+		hwnd:    hwnd,
+		button:  button,
+		resize:  resize,
+		startX:  pt.X,
+		startY:  pt.Y,
+		start:   *rect,
+		borders: w32.RECT{Left: frame.Left - rect.Left, Top: frame.Top - rect.Top, Right: rect.Right - frame.Right, Bottom: rect.Bottom - frame.Bottom},
+	}
+	d.sx, d.sy = dragCorner(*rect, pt.X, pt.Y)
+	dragging = d
+	// a lone Alt press and release would open the window's menu bar once
+	// the drag ends
+	maskModifierRelease(int(dragConfig.CombinedMod))
+	fmt.Printf("drag: start 0x%x resize=%v\n", hwnd, resize)
+	return true
+}
+
+func dragTo(pt w32.POINT) {
+	d := dragging
+	dx, dy := pt.X-d.startX, pt.Y-d.startY
+	var r w32.RECT
+	flags := uint(w32.SWP_NOZORDER | w32.SWP_NOACTIVATE | w32.SWP_ASYNCWINDOWPOS)
+	if d.resize {
+		r = dragResizeRect(d.start, d.sx, d.sy, dx, dy, minWindowSize)
+	} else {
+		r = dragMoveRect(d.start, dx, dy)
+		if dragConfig.SnapToEdges {
+			r = snapMovedWindow(r, d.borders, pt)
+		}
+		flags |= w32.SWP_NOSIZE
+	}
+	w32.SetWindowPos(d.hwnd, 0, int(r.Left), int(r.Top), int(r.Width()), int(r.Height()), flags)
+}
+
+// snapMovedWindow snaps the visible frame of a window at r to the work
+// area of the monitor under the cursor.
+func snapMovedWindow(r, borders w32.RECT, pt w32.POINT) w32.RECT {
+	var monInfo w32.MONITORINFO
+	if !w32.GetMonitorInfo(w32.MonitorFromPoint(int(pt.X), int(pt.Y), w32.MONITOR_DEFAULTTONEAREST), &monInfo) {
+		return r
+	}
+	frame := w32.RECT{Left: r.Left + borders.Left, Top: r.Top + borders.Top, Right: r.Right - borders.Right, Bottom: r.Bottom - borders.Bottom}
+	snapped := snapToEdges(frame, monInfo.RcWork, dragConfig.SnapDistance)
+	return dragMoveRect(r, snapped.Left-frame.Left, snapped.Top-frame.Top)
+}
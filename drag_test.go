@@ -0,0 +1,90 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestDragCorner(t *testing.T) {
+	r := w32.RECT{Left: 100, Top: 100, Right: 300, Bottom: 200}
+	cases := []struct {
+		x, y   int32
+		sx, sy int32
+	}{
+		{110, 110, -1, -1},
+		{290, 110, 1, -1},
+		{110, 190, -1, 1},
+		{290, 190, 1, 1},
+		{200, 150, 1, 1}, // dead center goes to the bottom-right
+	}
+	for _, c := range cases {
+		sx, sy := dragCorner(r, c.x, c.y)
+		if sx != c.sx || sy != c.sy {
+			t.Errorf("dragCorner(%d, %d) = (%d, %d), want (%d, %d)", c.x, c.y, sx, sy, c.sx, c.sy)
+		}
+	}
+}
+
+func TestDragResizeRect(t *testing.T) {
+	start := w32.RECT{Left: 100, Top: 100, Right: 500, Bottom: 400}
+	cases := []struct {
+		name           string
+		sx, sy, dx, dy int32
+		want           w32.RECT
+	}{
+		{"grow bottom-right", 1, 1, 50, 20, w32.RECT{Left: 100, Top: 100, Right: 550, Bottom: 420}},
+		{"grow top-left", -1, -1, -50, -20, w32.RECT{Left: 50, Top: 80, Right: 500, Bottom: 400}},
+		{"shrink top-right", 1, -1, -100, 100, w32.RECT{Left: 100, Top: 200, Right: 400, Bottom: 400}},
+		{"clamp right", 1, 1, -1000, 0, w32.RECT{Left: 100, Top: 100, Right: 200, Bottom: 400}},
+		{"clamp top", -1, -1, 0, 1000, w32.RECT{Left: 100, Top: 300, Right: 500, Bottom: 400}},
+	}
+	for _, c := range cases {
+		if got := dragResizeRect(start, c.sx, c.sy, c.dx, c.dy, 100); got != c.want {
+			t.Errorf("%s: dragResizeRect = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDragMoveRect(t *testing.T) {
+	got := dragMoveRect(w32.RECT{Left: 10, Top: 20, Right: 110, Bottom: 220}, -15, 30)
+	want := w32.RECT{Left: -5, Top: 50, Right: 95, Bottom: 250}
+	if got != want {
+		t.Errorf("dragMoveRect = %v, want %v", got, want)
+	}
+}
+
+func TestSnapToEdges(t *testing.T) {
+	work := w32.RECT{Left: 0, Top: 0, Right: 1920, Bottom: 1040}
+	cases := []struct {
+		name string
+		r    w32.RECT
+		want w32.RECT
+	}{
+		{"far from edges", w32.RECT{Left: 500, Top: 300, Right: 900, Bottom: 600}, w32.RECT{Left: 500, Top: 300, Right: 900, Bottom: 600}},
+		{"near left", w32.RECT{Left: 10, Top: 300, Right: 410, Bottom: 600}, w32.RECT{Left: 0, Top: 300, Right: 400, Bottom: 600}},
+		{"past left", w32.RECT{Left: -12, Top: 300, Right: 388, Bottom: 600}, w32.RECT{Left: 0, Top: 300, Right: 400, Bottom: 600}},
+		{"near bottom-right", w32.RECT{Left: 1510, Top: 735, Right: 1910, Bottom: 1035}, w32.RECT{Left: 1520, Top: 740, Right: 1920, Bottom: 1040}},
+		{"just outside distance", w32.RECT{Left: 17, Top: 17, Right: 417, Bottom: 317}, w32.RECT{Left: 17, Top: 17, Right: 417, Bottom: 317}},
+		{"wider than work area prefers left", w32.RECT{Left: 5, Top: 300, Right: 1925, Bottom: 600}, w32.RECT{Left: 0, Top: 300, Right: 1920, Bottom: 600}},
+	}
+	for _, c := range cases {
+		if got := snapToEdges(c.r, work, 16); got != c.want {
+			t.Errorf("%s: snapToEdges = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
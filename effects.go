@@ -0,0 +1,288 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Window effects: always on top (toggleAlwaysOnTop), opacity and
+// click-through. The state of every window changed is kept, with how the
+// window was before, so the tray can list the windows and put them back.
+
+// windowEffect is the state of one window. opacity is a percentage, 100
+// being opaque.
+type windowEffect struct {
+	title        string
+	pinned       bool
+	opacity      int32
+	clickThrough bool
+
+	// how the window was before the first change
+	origExStyle int32
+	origLayered layeredAttributes
+}
+
+type layeredAttributes struct {
+	key   w32.COLORREF
+	alpha byte
+	flags uint32
+}
+
+// selfLayered reports whether the window drew its own transparency with
+// UpdateLayeredWindow, which SetLayeredWindowAttributes would break.
+func (e windowEffect) selfLayered() bool {
+	return e.origExStyle&w32.WS_EX_LAYERED != 0 && e.origLayered.flags == 0
+}
+
+func (e windowEffect) origPinned() bool {
+	return e.origExStyle&w32.WS_EX_TOPMOST != 0
+}
+
+// active reports whether e differs from how the window was.
+func (e windowEffect) active() bool {
+	return e.pinned != e.origPinned() || e.opacity < 100 || e.clickThrough
+}
+
+// exStyle turns the extended window style cur into one giving the window
+// effect e, leaving the bits that don't concern it alone.
+func (e windowEffect) exStyle(cur int32) int32 {
+	const ours = w32.WS_EX_LAYERED | w32.WS_EX_TRANSPARENT
+	want := e.origExStyle & ours
+	if e.opacity < 100 || e.clickThrough {
+		want |= w32.WS_EX_LAYERED
+	}
+	if e.clickThrough {
+		want |= w32.WS_EX_TRANSPARENT
+	}
+	return cur&^ours | want
+}
+
+// describe lists the effects for the tray menu, e.g. "pinned, 70%".
+func (e windowEffect) describe() string {
+	var parts []string
+	if e.pinned && !e.origPinned() {
+		parts = append(parts, "pinned")
+	}
+	if !e.pinned && e.origPinned() {
+		parts = append(parts, "unpinned")
+	}
+	if e.opacity < 100 {
+		parts = append(parts, fmt.Sprintf("%d%%", e.opacity))
+	}
+	if e.clickThrough {
+		parts = append(parts, "click-through")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// stepOpacity changes opacity by delta percent, keeping it within
+// minimum-100.
+func stepOpacity(opacity, delta, minimum int32) int32 {
+	return max(minimum, min(100, opacity+delta))
+}
+
+// opacityConfig is set from the configuration in main.
+var opacityConfig = OpacityConfig{Step: DEFAULT_OPACITY_STEP, Transparent: DEFAULT_OPACITY_TRANSPARENT, Min: DEFAULT_OPACITY_MIN}
+
+var (
+	effectsMu sync.Mutex
+	effects   = map[w32.HWND]*windowEffect{}
+)
+
+// effectsWatchers are called after the effects of a window change, e.g. to
+// update the tray menu.
+var effectsWatchers []func()
+
+func watchEffects(f func()) {
+	effectsMu.Lock()
+	effectsWatchers = append(effectsWatchers, f)
+	effectsMu.Unlock()
+}
+
+func effectsChanged() {
+	effectsMu.Lock()
+	watchers := effectsWatchers
+	effectsMu.Unlock()
+	for _, f := range watchers {
+		f()
+	}
+}
+
+// currentEffect returns the state of hwnd, read from the window itself if
+// it wasn't changed before. Call with effectsMu held.
+func currentEffect(hwnd w32.HWND) *windowEffect {
+	if e, ok := effects[hwnd]; ok {
+		return e
+	}
+	e := &windowEffect{
+		title:       w32.GetWindowText(hwnd),
+		opacity:     100,
+		origExStyle: w32.GetWindowLong(hwnd, GWL_EXSTYLE),
+	}
+	e.pinned = e.origPinned()
+	if e.origExStyle&w32.WS_EX_LAYERED != 0 {
+		if key, alpha, flags, ok := w32ex.GetLayeredWindowAttributes(hwnd); ok {
+			e.origLayered = layeredAttributes{key, alpha, flags}
+		}
+	}
+	effects[hwnd] = e
+	return e
+}
+
+// changeEffect applies change to the state of hwnd and updates the window.
+func changeEffect(hwnd w32.HWND, change func(e *windowEffect)) error {
+	if !isZonableWindow(hwnd) {
+		return fmt.Errorf("not a zonable window: 0x%x", hwnd)
+	}
+	effectsMu.Lock()
+	e := currentEffect(hwnd)
+	prev := *e
+	change(e)
+	var err error
+	if e.selfLayered() && e.opacity != prev.opacity {
+		*e = prev
+		err = errors.New("the window draws its own transparency")
+	} else {
+		err = applyEffect(hwnd, *e)
+	}
+	if !e.active() {
+		delete(effects, hwnd)
+	}
+	effectsMu.Unlock()
+	effectsChanged()
+	return err
+}
+
+func applyEffect(hwnd w32.HWND, e windowEffect) error {
+	w32.SetWindowLong(hwnd, GWL_EXSTYLE, e.exStyle(w32.GetWindowLong(hwnd, GWL_EXSTYLE)))
+	if !e.selfLayered() && (e.opacity < 100 || e.clickThrough) {
+		if !w32.SetLayeredWindowAttributes(hwnd, 0, uint8(e.opacity*255/100), w32.LWA_ALPHA) {
+			return fmt.Errorf("failed to SetLayeredWindowAttributes: %d", w32.GetLastError())
+		}
+	} else if e.origExStyle&w32.WS_EX_LAYERED != 0 && e.origLayered.flags != 0 {
+		w32.SetLayeredWindowAttributes(hwnd, e.origLayered.key, e.origLayered.alpha, e.origLayered.flags)
+	}
+	insertAfter := w32.HWND_NOTOPMOST
+	if e.pinned {
+		insertAfter = w32.HWND_TOPMOST
+	}
+	if !w32.SetWindowPos(hwnd, insertAfter, 0, 0, 0, 0, w32.SWP_NOMOVE|w32.SWP_NOSIZE|w32.SWP_NOACTIVATE) {
+		return fmt.Errorf("failed to SetWindowPos: %d", w32.GetLastError())
+	}
+	return nil
+}
+
+// resetEffects puts hwnd back the way it was before any change.
+func resetEffects(hwnd w32.HWND) {
+	effectsMu.Lock()
+	e, ok := effects[hwnd]
+	delete(effects, hwnd)
+	effectsMu.Unlock()
+	if !ok {
+		return
+	}
+	if w32.IsWindow(hwnd) {
+		orig := *e
+		orig.pinned, orig.opacity, orig.clickThrough = e.origPinned(), 100, false
+		if err := applyEffect(hwnd, orig); err != nil {
+			fmt.Printf("warn: reset window effects: %v\n", err)
+		}
+	}
+	effectsChanged()
+}
+
+func resetAllEffects() {
+	for _, w := range effectWindows() {
+		resetEffects(w.hwnd)
+	}
+}
+
+// releaseEffects undoes, on exit, the effects that would strand the user
+// with nothing left to undo them: click-through windows can't be clicked,
+// and transparency. Pinned windows stay pinned, as they always did.
+func releaseEffects() {
+	for _, w := range effectWindows() {
+		if w.effect.opacity == 100 && !w.effect.clickThrough {
+			continue
+		}
+		if err := changeEffect(w.hwnd, func(e *windowEffect) {
+			e.opacity, e.clickThrough = 100, false
+		}); err != nil {
+			fmt.Printf("warn: release window effects: %v\n", err)
+		}
+	}
+}
+
+type effectWindow struct {
+	hwnd   w32.HWND
+	effect windowEffect
+}
+
+// effectWindows lists the windows with effects, dropping those closed
+// since, in a stable order.
+func effectWindows() []effectWindow {
+	effectsMu.Lock()
+	defer effectsMu.Unlock()
+	var list []effectWindow
+	for hwnd, e := range effects {
+		if !w32.IsWindow(hwnd) {
+			delete(effects, hwnd)
+			continue
+		}
+		list = append(list, effectWindow{hwnd, *e})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].hwnd < list[j].hwnd })
+	return list
+}
+
+func effectFeature(name string, change func(e *windowEffect)) func() {
+	return func() {
+		if err := changeEffect(getTargetWindow(), change); err != nil {
+			fmt.Printf("warn: %s: %v\n", name, err)
+		}
+	}
+}
+
+func toggleTransparency(e *windowEffect) {
+	if e.opacity < 100 {
+		e.opacity = 100
+	} else {
+		e.opacity = opacityConfig.Transparent
+	}
+}
+
+func opacityUp(e *windowEffect) {
+	e.opacity = stepOpacity(e.opacity, opacityConfig.Step, opacityConfig.Min)
+}
+
+func opacityDown(e *windowEffect) {
+	e.opacity = stepOpacity(e.opacity, -opacityConfig.Step, opacityConfig.Min)
+}
+
+func toggleClickThrough(e *windowEffect) {
+	e.clickThrough = !e.clickThrough
+}
+
+func togglePinned(e *windowEffect) {
+	e.pinned = !e.pinned
+}
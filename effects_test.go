@@ -0,0 +1,92 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestWindowEffectExStyle(t *testing.T) {
+	const other = 0x100 // WS_EX_WINDOWEDGE, which effects leave alone
+	tests := []struct {
+		name   string
+		effect windowEffect
+		want   int32
+	}{
+		{"unchanged", windowEffect{opacity: 100}, other},
+		{"transparent", windowEffect{opacity: 70}, other | w32.WS_EX_LAYERED},
+		{"click-through", windowEffect{opacity: 100, clickThrough: true},
+			other | w32.WS_EX_LAYERED | w32.WS_EX_TRANSPARENT},
+		{"restores own layering", windowEffect{opacity: 100, origExStyle: w32.WS_EX_LAYERED},
+			other | w32.WS_EX_LAYERED},
+	}
+	for _, tt := range tests {
+		cur := int32(other | w32.WS_EX_LAYERED | w32.WS_EX_TRANSPARENT)
+		if got := tt.effect.exStyle(cur); got != tt.want {
+			t.Errorf("%s: exStyle = 0x%x, want 0x%x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWindowEffectState(t *testing.T) {
+	tests := []struct {
+		effect   windowEffect
+		active   bool
+		describe string
+	}{
+		{windowEffect{opacity: 100}, false, ""},
+		{windowEffect{opacity: 100, pinned: true, origExStyle: w32.WS_EX_TOPMOST}, false, ""},
+		{windowEffect{opacity: 100, pinned: true}, true, "pinned"},
+		{windowEffect{opacity: 100, origExStyle: w32.WS_EX_TOPMOST}, true, "unpinned"},
+		{windowEffect{opacity: 70, pinned: true, clickThrough: true}, true, "pinned, 70%, click-through"},
+	}
+	for _, tt := range tests {
+		if got := tt.effect.active(); got != tt.active {
+			t.Errorf("%+v: active = %v, want %v", tt.effect, got, tt.active)
+		}
+		if got := tt.effect.describe(); got != tt.describe {
+			t.Errorf("%+v: describe = %q, want %q", tt.effect, got, tt.describe)
+		}
+	}
+}
+
+func TestWindowEffectSelfLayered(t *testing.T) {
+	if (windowEffect{}).selfLayered() {
+		t.Error("a window that isn't layered is not self-layered")
+	}
+	if !(windowEffect{origExStyle: w32.WS_EX_LAYERED}).selfLayered() {
+		t.Error("a layered window without layered attributes is self-layered")
+	}
+	if (windowEffect{origExStyle: w32.WS_EX_LAYERED, origLayered: layeredAttributes{alpha: 200, flags: w32.LWA_ALPHA}}).selfLayered() {
+		t.Error("a layered window with layered attributes is not self-layered")
+	}
+}
+
+func TestStepOpacity(t *testing.T) {
+	tests := []struct{ opacity, delta, want int32 }{
+		{100, -10, 90},
+		{90, 10, 100},
+		{100, 10, 100},
+		{25, -10, 20},
+		{20, -10, 20},
+	}
+	for _, tt := range tests {
+		if got := stepOpacity(tt.opacity, tt.delta, 20); got != tt.want {
+			t.Errorf("stepOpacity(%d, %d, 20) = %d, want %d", tt.opacity, tt.delta, got, tt.want)
+		}
+	}
+}
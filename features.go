@@ -0,0 +1,198 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// featureConstructor builds the callback for a feature. args holds the
+// value given after the feature name in config.yaml, e.g. the 2 in
+// `bindfeature: {moveToDisplay: 2}`, and is nil for a plain
+// `bindfeature: moveToDisplay`.
+type featureConstructor func(args *yaml.Node) (func(), error)
+
+type featureRegistration struct {
+	DisplayName string
+	New         featureConstructor
+}
+
+// featureRegistry holds every feature that can be bound to a hotkey,
+// keyed by the name used in bindfeature. It is populated by
+// registerFeatures.
+var featureRegistry map[string]featureRegistration
+
+// featureNames returns the names of the registered features, sorted.
+func featureNames() []string {
+	names := make([]string, 0, len(featureRegistry))
+	for name := range featureRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// simpleFeature registers a feature that takes no arguments.
+func simpleFeature(displayName string, callback func()) featureRegistration {
+	return featureRegistration{displayName, func(args *yaml.Node) (func(), error) {
+		if args != nil {
+			return nil, errors.New("takes no arguments")
+		}
+		return callback, nil
+	}}
+}
+
+// newFeature resolves a feature name and its arguments into a callback.
+func newFeature(name string, args *yaml.Node) (func(), error) {
+	reg, ok := featureRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown feature: %s", name)
+	}
+	callback, err := reg.New(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return callback, nil
+}
+
+// describeArgs writes feature arguments on one line, as in config.yaml,
+// e.g. "2" or "{dx: 100, dy: 0}".
+func describeArgs(args *yaml.Node) string {
+	if args == nil {
+		return ""
+	}
+	if args.Kind == yaml.ScalarNode {
+		return args.Value
+	}
+	flow := *args
+	flow.Style = yaml.FlowStyle
+	data, err := yaml.Marshal(&flow)
+	if err != nil {
+		return "..."
+	}
+	return strings.TrimSpace(string(data))
+}
+
+type deltaArgs struct {
+	DX int32 `yaml:"dx"`
+	DY int32 `yaml:"dy"`
+}
+
+func decodeDeltaArgs(args *yaml.Node) (deltaArgs, error) {
+	var d deltaArgs
+	if args == nil {
+		return d, errors.New("takes {dx: N, dy: N} in pixels")
+	}
+	if err := args.Decode(&d); err != nil {
+		return d, fmt.Errorf("takes {dx: N, dy: N} in pixels: %v", err)
+	}
+	return d, nil
+}
+
+// newMoveToDisplayFeature takes a 1-based display number, in the same order
+// as the monitors printed on startup.
+func newMoveToDisplayFeature(args *yaml.Node) (func(), error) {
+	var n int
+	if args == nil || args.Decode(&n) != nil || n < 1 {
+		return nil, errors.New("takes a display number starting from 1")
+	}
+	return func() {
+		lastResized = 0
+		if _, err := resizeToDisplay(getTargetWindow(), center, n-1); err != nil {
+			fmt.Printf("warn: resize: %v\n", err)
+		}
+	}, nil
+}
+
+// newMoveToDesktopFeature takes a 1-based virtual desktop number, in the
+// order of Task View.
+func newMoveToDesktopFeature(args *yaml.Node) (func(), error) {
+	var n int
+	if args == nil || args.Decode(&n) != nil || n < 1 {
+		return nil, errors.New("takes a desktop number starting from 1")
+	}
+	return func() {
+		if err := moveToDesktop(desktops, getTargetWindow(), n-1); err != nil {
+			fmt.Printf("warn: move to desktop: %v\n", err)
+		}
+	}, nil
+}
+
+func newResizeByFeature(args *yaml.Node) (func(), error) {
+	d, err := decodeDeltaArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if _, err := resizeLinked(getTargetWindow(), resizeBy(d.DX, d.DY)); err != nil {
+			fmt.Printf("warn: resize: %v\n", err)
+		}
+	}, nil
+}
+
+func newMoveByFeature(args *yaml.Node) (func(), error) {
+	d, err := decodeDeltaArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if _, err := resize(getTargetWindow(), moveBy(d.DX, d.DY)); err != nil {
+			fmt.Printf("warn: resize: %v\n", err)
+		}
+	}, nil
+}
+
+// layoutFeature moves the target window to a fixed layout such as
+// firstThird.
+func layoutFeature(f resizeFunc) func() {
+	return func() {
+		lastResized = 0
+		if _, err := resize(getTargetWindow(), f); err != nil {
+			fmt.Printf("warn: resize: %v\n", err)
+		}
+	}
+}
+
+func swapFeature(d direction) func() {
+	return func() {
+		if err := swapWithNeighbor(d); err != nil {
+			fmt.Printf("warn: swap: %v\n", err)
+		}
+	}
+}
+
+func focusFeature(d direction) func() {
+	return func() {
+		if err := focusInDirection(d); err != nil {
+			fmt.Printf("warn: focus: %v\n", err)
+		}
+	}
+}
+
+var resizeConfig = ResizeConfig{EdgeStepSize: resizeStep{Percent: 5}, Options: defaultResizeOptions}
+
+// edgeFeature moves one edge of the target window by the configured step.
+func edgeFeature(edge direction, grow bool) func() {
+	return func() {
+		if _, err := resizeLinked(getTargetWindow(), moveEdge(edge, grow, resizeConfig.EdgeStepSize)); err != nil {
+			fmt.Printf("warn: resize: %v\n", err)
+		}
+	}
+}
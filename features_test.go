@@ -0,0 +1,136 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func yamlNode(t *testing.T, src string) *yaml.Node {
+	t.Helper()
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatalf("yaml.Unmarshal(%q): %v", src, err)
+	}
+	return doc.Content[0]
+}
+
+func TestNewFeature(t *testing.T) {
+	called := false
+	featureRegistry = map[string]featureRegistration{
+		"plain":         simpleFeature("Plain", func() { called = true }),
+		"moveToDisplay": {"Move to Display", newMoveToDisplayFeature},
+	}
+	defer func() { featureRegistry = nil }()
+
+	callback, err := newFeature("plain", nil)
+	if err != nil {
+		t.Fatalf("newFeature(plain) unexpected error: %v", err)
+	}
+	callback()
+	if !called {
+		t.Error("newFeature(plain) returned the wrong callback")
+	}
+
+	if _, err := newFeature("plain", yamlNode(t, "1")); err == nil {
+		t.Error("newFeature(plain, 1) expected error for unexpected arguments")
+	}
+	if _, err := newFeature("missing", nil); err == nil || !strings.Contains(err.Error(), "unknown feature") {
+		t.Errorf("newFeature(missing) error = %v, want unknown feature", err)
+	}
+	if _, err := newFeature("moveToDisplay", nil); err == nil || !strings.HasPrefix(err.Error(), "moveToDisplay:") {
+		t.Errorf("newFeature(moveToDisplay) error = %v, want it prefixed with the feature name", err)
+	}
+}
+
+func TestFeatureNames(t *testing.T) {
+	featureRegistry = map[string]featureRegistration{
+		"moveToTop":  simpleFeature("Top half", func() {}),
+		"cheatSheet": simpleFeature("Hotkey Cheat Sheet", func() {}),
+		"maximize":   simpleFeature("Maximize", func() {}),
+	}
+	defer func() { featureRegistry = nil }()
+	if got, want := strings.Join(featureNames(), ","), "cheatSheet,maximize,moveToTop"; got != want {
+		t.Errorf("featureNames() = %s, want %s", got, want)
+	}
+}
+
+func TestNewMoveToDisplayFeature(t *testing.T) {
+	cases := []struct {
+		args    string
+		wantErr bool
+	}{
+		{"1", false},
+		{"3", false},
+		{"0", true},
+		{"-1", true},
+		{"two", true},
+		{"{dx: 1}", true},
+	}
+	for _, c := range cases {
+		_, err := newMoveToDisplayFeature(yamlNode(t, c.args))
+		if (err != nil) != c.wantErr {
+			t.Errorf("newMoveToDisplayFeature(%s) error = %v, wantErr %v", c.args, err, c.wantErr)
+		}
+	}
+	if _, err := newMoveToDisplayFeature(nil); err == nil {
+		t.Error("newMoveToDisplayFeature(nil) expected error")
+	}
+}
+
+func TestDecodeDeltaArgs(t *testing.T) {
+	d, err := decodeDeltaArgs(yamlNode(t, "{dx: 100}"))
+	if err != nil {
+		t.Fatalf("decodeDeltaArgs unexpected error: %v", err)
+	}
+	if d.DX != 100 || d.DY != 0 {
+		t.Errorf("decodeDeltaArgs = %+v, want {DX:100 DY:0}", d)
+	}
+	d, err = decodeDeltaArgs(yamlNode(t, "{dx: -10, dy: 20}"))
+	if err != nil || d.DX != -10 || d.DY != 20 {
+		t.Errorf("decodeDeltaArgs = %+v, %v, want {DX:-10 DY:20}", d, err)
+	}
+	if _, err := decodeDeltaArgs(nil); err == nil {
+		t.Error("decodeDeltaArgs(nil) expected error")
+	}
+	if _, err := decodeDeltaArgs(yamlNode(t, "5")); err == nil {
+		t.Error("decodeDeltaArgs(5) expected error")
+	}
+	if _, err := newResizeByFeature(yamlNode(t, "{dy: 10}")); err != nil {
+		t.Errorf("newResizeByFeature unexpected error: %v", err)
+	}
+	if _, err := newMoveByFeature(nil); err == nil {
+		t.Error("newMoveByFeature(nil) expected error")
+	}
+}
+
+func TestDescribeArgs(t *testing.T) {
+	cases := []struct{ args, want string }{
+		{"2", "2"},
+		{"{dx: 100, dy: -50}", "{dx: 100, dy: -50}"},
+		{"dx: 100\ndy: -50", "{dx: 100, dy: -50}"},
+	}
+	for _, c := range cases {
+		if got := describeArgs(yamlNode(t, c.args)); got != c.want {
+			t.Errorf("describeArgs(%q) = %q, want %q", c.args, got, c.want)
+		}
+	}
+	if got := describeArgs(nil); got != "" {
+		t.Errorf("describeArgs(nil) = %q, want empty", got)
+	}
+}
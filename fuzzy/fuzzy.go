@@ -0,0 +1,141 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fuzzy matches typed queries against strings, the way quick-open
+// pickers do: the letters of the query must appear in order but not
+// necessarily together, and matches at word starts and in runs rank higher.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreMatch       = 16
+	bonusWordStart   = 12
+	bonusConsecutive = 8
+	penaltyGap       = 1
+)
+
+// Match reports whether every space-separated term of query matches s and
+// scores the match, higher being better. Case is ignored. An empty query
+// matches anything with score 0.
+func Match(query, s string) (int, bool) {
+	text := []rune(s)
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+	total := 0
+	for _, term := range strings.Fields(query) {
+		score, ok := matchTerm([]rune(strings.ToLower(term)), text, lower)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+// matchTerm finds the best placement of the runes of term in lower, the
+// lowercase text, with dynamic programming: best[j] is the best score of
+// the term so far with its last rune at text[j].
+func matchTerm(term, text, lower []rune) (int, bool) {
+	const none = -1 << 30
+	prev := make([]int, len(lower))
+	best := make([]int, len(lower))
+	for i, r := range term {
+		// the best of prev[k] + k for k < j-1, for a match at j after a gap
+		runMax := none
+		for j := range lower {
+			best[j] = none
+			if j >= 2 && i > 0 && prev[j-2] != none {
+				runMax = max(runMax, prev[j-2]+j-2)
+			}
+			if lower[j] != r {
+				continue
+			}
+			score := scoreMatch
+			if wordStart(text, j) {
+				score += bonusWordStart
+			}
+			if i == 0 {
+				best[j] = score
+				continue
+			}
+			if j >= 1 && prev[j-1] != none {
+				best[j] = prev[j-1] + score + bonusConsecutive
+			}
+			if runMax != none {
+				best[j] = max(best[j], runMax-(j-1)*penaltyGap+score)
+			}
+		}
+		prev, best = best, prev
+	}
+	result := none
+	for _, s := range prev {
+		result = max(result, s)
+	}
+	return result, len(term) > 0 && result != none
+}
+
+// wordStart reports whether text[j] begins a word: it follows a
+// separator, or is an upper case letter after a lower case one, or a
+// digit after a letter.
+func wordStart(text []rune, j int) bool {
+	if j == 0 {
+		return true
+	}
+	p, c := text[j-1], text[j]
+	switch {
+	case !unicode.IsLetter(p) && !unicode.IsDigit(p):
+		return unicode.IsLetter(c) || unicode.IsDigit(c)
+	case unicode.IsLower(p) && unicode.IsUpper(c):
+		return true
+	case unicode.IsLetter(p) && unicode.IsDigit(c):
+		return true
+	}
+	return false
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Result is an item of Rank.
+type Result struct {
+	// Index of the item in the items given to Rank.
+	Index int
+	Score int
+}
+
+// Rank returns the items matching query, best first. Items with equal
+// scores keep their order.
+func Rank(query string, items []string) []Result {
+	var results []Result
+	for i, item := range items {
+		if score, ok := Match(query, item); ok {
+			results = append(results, Result{i, score})
+		}
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		return results[a].Score > results[b].Score
+	})
+	return results
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query, s string
+		ok       bool
+	}{
+		{"", "anything", true},
+		{"ntp", "Notepad", true},
+		{"NOTE", "notepad", true},
+		{"pn", "Notepad", false},
+		{"chr git", "GitHub - Google Chrome", true},
+		{"chr gitlab", "GitHub - Google Chrome", false},
+		{"über", "Über uns - Firefox", true},
+		{"x", "", false},
+	}
+	for _, tt := range tests {
+		if _, ok := Match(tt.query, tt.s); ok != tt.ok {
+			t.Errorf("Match(%q, %q) ok = %v, want %v", tt.query, tt.s, ok, tt.ok)
+		}
+	}
+}
+
+func TestMatchScores(t *testing.T) {
+	// each query should score higher against better than against worse
+	tests := []struct{ query, better, worse string }{
+		// consecutive letters beat scattered ones
+		{"note", "notes.txt", "Notification Center"},
+		// word starts beat letters inside words
+		{"vsc", "Visual Studio Code", "vsxxc"},
+		{"wt", "Windows Terminal", "switch"},
+		// camel case humps count as word starts
+		{"gd", "GoogleDocs", "good"},
+		// shorter gaps beat longer ones
+		{"ab", "a-b", "a----b"},
+	}
+	for _, tt := range tests {
+		better, ok1 := Match(tt.query, tt.better)
+		worse, ok2 := Match(tt.query, tt.worse)
+		if !ok1 || !ok2 {
+			t.Errorf("%q should match both %q and %q", tt.query, tt.better, tt.worse)
+			continue
+		}
+		if better <= worse {
+			t.Errorf("%q: %q scored %d, not above %q with %d", tt.query, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestMatchBestPlacement(t *testing.T) {
+	// a greedy match would take the first "a" and score worse than the run
+	// at the end
+	greedy, _ := Match("abc", "a xx abc")
+	run, _ := Match("abc", "abc")
+	if greedy != run {
+		t.Errorf("Match found a worse placement: %d, want %d", greedy, run)
+	}
+}
+
+func TestRank(t *testing.T) {
+	items := []string{
+		"Inbox - Outlook",
+		"notepad.exe - Untitled",
+		"Downloads - File Explorer",
+		"README.md - Notepad",
+		"Settings",
+	}
+	indices := func(results []Result) []int {
+		var out []int
+		for _, r := range results {
+			out = append(out, r.Index)
+		}
+		return out
+	}
+	if got, want := indices(Rank("notepad", items)), []int{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank(notepad) = %v, want %v", got, want)
+	}
+	if got, want := indices(Rank("fe", items)), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank(fe) = %v, want %v", got, want)
+	}
+	// without a query everything matches, in the original order
+	if got, want := indices(Rank(" ", items)), []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank of an empty query = %v, want %v", got, want)
+	}
+}
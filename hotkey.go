@@ -16,6 +16,8 @@ package main
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gonutz/w32/v2"
 	"github.com/phoeagon/RectangleWinPlus/w32ex"
@@ -29,6 +31,10 @@ type HotKey struct {
 	id, mod, vk int
 	callback    func()
 	bindFeature string
+	// hasArgs is set when the binding passes arguments to its feature,
+	// e.g. bindfeature: {moveToDisplay: 2}, and args describes them.
+	hasArgs bool
+	args    string
 }
 
 func (h HotKey) String() string { return fmt.Sprintf("mod=0x%x,vk=%d", h.mod, h.vk) }
@@ -55,12 +61,23 @@ func (h HotKey) Describe() string {
 	return out
 }
 
+// ShortDescribe is Describe without the " key" suffix, for compact hints.
+func (h HotKey) ShortDescribe() string {
+	return strings.TrimSuffix(h.Describe(), " key")
+}
+
 func RegisterHotKey(h HotKey) bool {
 	fmt.Printf("registering hotkey: %v\n", h)
 	if _, ok := hotkeyRegistrations[h.id]; ok {
-		panic("hotkey id already registered") // TODO ok for now
+		fmt.Printf("warn: hotkey id %d already registered, skipping %v\n", h.id, h)
+		return false
+	}
+	var ok bool
+	if isMouseKey(h.vk) {
+		ok = registerMouseBinding(h)
+	} else {
+		ok = w32ex.RegisterHotKey(0, h.id, h.mod, h.vk)
 	}
-	ok := w32ex.RegisterHotKey(0, h.id, h.mod, h.vk)
 	if ok {
 		fmt.Printf("registered hotkey: %v\n", h)
 		hotkeyRegistrations[h.id] = &h
@@ -69,10 +86,18 @@ func RegisterHotKey(h HotKey) bool {
 }
 
 func UnregisterHotKey(h HotKey) {
+	if unregisterHookedHotKey(h) {
+		return
+	}
 	if hotkeyRegistrations[h.id] == nil {
 		fmt.Printf("warn: hotkey not registered: %v\n", h)
 		return
 	}
+	if isMouseKey(h.vk) {
+		unregisterMouseBinding(h)
+		delete(hotkeyRegistrations, h.id)
+		return
+	}
 	ok := w32ex.UnregisterHotKey(0, h.id)
 	if !ok {
 		fmt.Printf("warn: failed to unregister hotkey: %v\n", h)
@@ -82,8 +107,13 @@ func UnregisterHotKey(h HotKey) {
 	}
 }
 
+// msgLoopThread is the thread running msgLoop, for other threads to post
+// messages to.
+var msgLoopThread uint32
+
 func msgLoop() error {
 	defer fmt.Println("event loop finished")
+	msgLoopThread = w32ex.GetCurrentThreadId()
 	for {
 		var m w32.MSG
 		c := w32.GetMessage(&m, 0, 0, 0)
@@ -93,7 +123,24 @@ func msgLoop() error {
 			// WM_QUIT received
 			return nil
 		}
-		if m.Message == w32.WM_HOTKEY {
+		if m.Message == w32.WM_TIMER && leaderTimer != 0 && m.WParam == leaderTimer {
+			expireLeaderMode()
+		} else if m.Message == w32.WM_TIMER && retileTimer != 0 && m.WParam == retileTimer {
+			retileTimerFired()
+		} else if m.Message == w32.WM_TIMER && cheatSheetTimer != 0 && m.WParam == cheatSheetTimer {
+			cheatSheetTick()
+		} else if m.Message == w32.WM_TIMER && hotkeyRetryTimer != 0 && m.WParam == hotkeyRetryTimer {
+			retryFailedHotKeys()
+		} else if m.Message == msgRetryHotKeys {
+			retryFailedHotKeys()
+		} else if m.Message == msgShowCheatSheet {
+			showCheatSheet()
+		} else if m.Message == msgUpdatePinBorders {
+			updatePinBorders()
+		} else if pickerHwnd != 0 && m.Hwnd == pickerHwnd {
+			w32.TranslateMessage(&m)
+			w32.DispatchMessage(&m)
+		} else if m.Message == w32.WM_HOTKEY {
 			h, ok := hotkeyRegistrations[int(m.WParam)]
 			if !ok {
 				return fmt.Errorf("hotkey without callback: %#v", m)
@@ -113,3 +160,78 @@ func unregisterAllHotKeys() {
 		UnregisterHotKey(*h)
 	}
 }
+
+// Leader-key mode. The leader hotkey registers the second-stage keys as
+// temporary hotkeys (ids from leaderHotKeyIDBase, a block of their own
+// above the ids used for configured bindings and adjust mode, so any
+// number of leader keys fits) and unregisters them again on the first
+// press, Escape, or when the thread timer fires. If one of them can't be
+// registered, leader mode is cancelled rather than letting that key reach
+// the focused window.
+const leaderHotKeyIDBase = 0x8000
+
+var (
+	leader        *leaderMachine
+	leaderHotKeys []HotKey
+	leaderTimer   uintptr
+)
+
+func enterLeaderMode() {
+	if leader == nil || !leader.Start(time.Now()) {
+		return
+	}
+	keys := append(leader.Keys(), leaderKey{vk: w32.VK_ESCAPE})
+	for i, k := range keys {
+		k := k
+		hk := HotKey{
+			id:          leaderHotKeyIDBase + i,
+			mod:         int(k.mod) | MOD_NOREPEAT,
+			vk:          int(k.vk),
+			callback:    func() { leaderPress(k) },
+			bindFeature: "leader",
+		}
+		if !RegisterHotKey(hk) {
+			fmt.Printf("warn: leader key in use by another process, cancelling leader mode: %s\n", hk.Describe())
+			exitLeaderMode()
+			return
+		}
+		leaderHotKeys = append(leaderHotKeys, hk)
+	}
+	leaderTimer = w32.SetTimer(0, 0, uint(leader.timeout/time.Millisecond), 0)
+	showOverlay(leader.Hint())
+}
+
+func leaderPress(k leaderKey) {
+	kb, ok := leader.Press(k, time.Now())
+	exitLeaderMode()
+	if !ok {
+		return
+	}
+	callback, err := newFeature(kb.BindFeature, kb.Args)
+	if err != nil {
+		fmt.Printf("warn: leader: %v\n", err)
+		return
+	}
+	fmt.Printf("trace: leader -> %s\n", kb.BindFeature)
+	callback()
+}
+
+func expireLeaderMode() {
+	if leader.Expire(time.Now()) {
+		fmt.Println("leader timed out")
+	}
+	exitLeaderMode()
+}
+
+func exitLeaderMode() {
+	leader.Cancel()
+	if leaderTimer != 0 {
+		w32ex.KillTimer(0, leaderTimer)
+		leaderTimer = 0
+	}
+	for _, hk := range leaderHotKeys {
+		UnregisterHotKey(hk)
+	}
+	leaderHotKeys = nil
+	hideOverlay()
+}
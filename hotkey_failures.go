@@ -0,0 +1,159 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Hotkeys that failed to register, usually because another program holds
+// them. They are kept to be retried, every HotkeysConfig.RetrySeconds on
+// a thread timer and from the tray menu, since that program may exit. The
+// tray marks the features they belong to.
+//
+// With HotkeysConfig.KeyboardHook a failed keyboard hotkey is caught by a
+// low-level keyboard hook instead, see keyhook.go, and isn't kept here.
+
+// posted to the msgLoop thread to retry the failed hotkeys
+const msgRetryHotKeys = w32.WM_APP + 3
+
+var (
+	failedHotKeys hotkeyFailures
+	// called on the msgLoop thread when hotkeys stop failing; added to
+	// from the tray's goroutine
+	hotkeyWatchersMu sync.Mutex
+	hotkeyWatchers   []func()
+	hotkeyRetryTimer uintptr
+)
+
+// hotkeyFailures is the set of failed hotkeys by id. The tray reads it
+// from its own goroutines.
+type hotkeyFailures struct {
+	mu  sync.Mutex
+	hks map[int]HotKey
+}
+
+// set adds or removes hk, reporting whether the set changed.
+func (f *hotkeyFailures) set(hk HotKey, failed bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.hks[hk.id]; ok == failed {
+		return false
+	}
+	if failed {
+		if f.hks == nil {
+			f.hks = map[int]HotKey{}
+		}
+		f.hks[hk.id] = hk
+	} else {
+		delete(f.hks, hk.id)
+	}
+	return true
+}
+
+// list returns the failed hotkeys ordered by id, which is config order.
+func (f *hotkeyFailures) list() []HotKey {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var list []HotKey
+	for _, hk := range f.hks {
+		list = append(list, hk)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].id < list[j].id })
+	return list
+}
+
+func watchHotkeys(f func()) {
+	hotkeyWatchersMu.Lock()
+	hotkeyWatchers = append(hotkeyWatchers, f)
+	hotkeyWatchersMu.Unlock()
+}
+
+func hotkeysChanged() {
+	hotkeyWatchersMu.Lock()
+	watchers := hotkeyWatchers
+	hotkeyWatchersMu.Unlock()
+	for _, f := range watchers {
+		f()
+	}
+}
+
+// duplicateHotKey returns the hotkey among earlier with the id or key
+// combination of hk, e.g. a combination bound twice in the configuration.
+// Registering hk fails because of it, not because another process holds
+// the combination, so retrying it would never help.
+func duplicateHotKey(earlier []HotKey, hk HotKey) (HotKey, bool) {
+	for _, e := range earlier {
+		if e.id == hk.id || (e.mod == hk.mod && e.vk == hk.vk) {
+			return e, true
+		}
+	}
+	return HotKey{}, false
+}
+
+// retryHotKeys tries register again on each failed hotkey, returning the
+// ones that succeeded.
+func retryHotKeys(failures *hotkeyFailures, register func(HotKey) bool) []HotKey {
+	var recovered []HotKey
+	for _, hk := range failures.list() {
+		if register(hk) {
+			failures.set(hk, false)
+			recovered = append(recovered, hk)
+		}
+	}
+	return recovered
+}
+
+// retryFailedHotKeys retries the failed hotkeys on the msgLoop thread,
+// which the hotkeys are registered to, stopping the retry timer once none
+// are left.
+func retryFailedHotKeys() {
+	recovered := retryHotKeys(&failedHotKeys, RegisterHotKey)
+	for _, hk := range recovered {
+		fmt.Printf("hotkey is free again: %s (%s)\n", hk.Describe(), hk.bindFeature)
+	}
+	if len(failedHotKeys.list()) == 0 && hotkeyRetryTimer != 0 {
+		w32ex.KillTimer(0, hotkeyRetryTimer)
+		hotkeyRetryTimer = 0
+	}
+	if len(recovered) > 0 {
+		hotkeysChanged()
+	}
+}
+
+// featureMenuTitle is the tray menu title of f, marking the hotkeys of f
+// among failed.
+func featureMenuTitle(f Feature, failed []HotKey) string {
+	title := f.DisplayName
+	if f.HotkeyDesc != "" {
+		title += fmt.Sprintf(" (%s)", f.HotkeyDesc)
+	}
+	var descs []string
+	for _, hk := range failed {
+		if hk.bindFeature == f.Name && !hk.hasArgs {
+			descs = append(descs, hk.ShortDescribe())
+		}
+	}
+	if len(descs) > 0 {
+		title += fmt.Sprintf(" ⚠ %s in use", strings.Join(descs, ", "))
+	}
+	return title
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHotkeyFailures(t *testing.T) {
+	var f hotkeyFailures
+	a := HotKey{id: 202, bindFeature: "maximize"}
+	b := HotKey{id: 201, bindFeature: "moveToLeft"}
+	if !f.set(a, true) || !f.set(b, true) {
+		t.Fatal("set of new failures should report a change")
+	}
+	if f.set(a, true) {
+		t.Error("set of a known failure should report no change")
+	}
+	ids := func() []int {
+		var out []int
+		for _, hk := range f.list() {
+			out = append(out, hk.id)
+		}
+		return out
+	}
+	if got, want := ids(), []int{201, 202}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list = %v, want %v in id order", got, want)
+	}
+
+	// only the hotkeys register succeeds on are recovered
+	recovered := retryHotKeys(&f, func(hk HotKey) bool { return hk.id == 202 })
+	if len(recovered) != 1 || recovered[0].id != 202 {
+		t.Errorf("retryHotKeys recovered %v, want 202", recovered)
+	}
+	if got, want := ids(), []int{201}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after retry list = %v, want %v", got, want)
+	}
+	if f.set(a, false) {
+		t.Error("removing a hotkey not failing should report no change")
+	}
+}
+
+func TestRegisterHotKeyDuplicateID(t *testing.T) {
+	hk := HotKey{id: 9999, mod: MOD_CONTROL | MOD_ALT, vk: 'Z'}
+	if !RegisterHotKey(hk) {
+		t.Fatal("RegisterHotKey failed")
+	}
+	defer delete(hotkeyRegistrations, hk.id)
+	dup := HotKey{id: 9999, mod: MOD_CONTROL | MOD_ALT, vk: 'Y'}
+	if RegisterHotKey(dup) {
+		t.Error("RegisterHotKey of a duplicate id should fail")
+	}
+	if hotkeyRegistrations[hk.id].vk != 'Z' {
+		t.Error("RegisterHotKey of a duplicate id replaced the first hotkey")
+	}
+}
+
+func TestDuplicateHotKey(t *testing.T) {
+	earlier := []HotKey{
+		{id: 201, mod: MOD_CONTROL | MOD_ALT, vk: 'C', bindFeature: "moveToCenter"},
+		{id: 202, mod: MOD_CONTROL | MOD_ALT, vk: 'M', bindFeature: "maximize"},
+	}
+	for _, tt := range []struct {
+		hk   HotKey
+		want string
+	}{
+		{HotKey{id: 203, mod: MOD_CONTROL | MOD_ALT, vk: 'M', bindFeature: "minimize"}, "maximize"},
+		{HotKey{id: 201, mod: MOD_CONTROL | MOD_ALT, vk: 'X', bindFeature: "minimize"}, "moveToCenter"},
+		{HotKey{id: 203, mod: MOD_CONTROL | MOD_SHIFT, vk: 'M', bindFeature: "minimize"}, ""},
+	} {
+		dup, ok := duplicateHotKey(earlier, tt.hk)
+		if ok != (tt.want != "") || dup.bindFeature != tt.want {
+			t.Errorf("duplicateHotKey(%v) = %q, %v; want %q", tt.hk, dup.bindFeature, ok, tt.want)
+		}
+	}
+}
+
+func TestFeatureMenuTitle(t *testing.T) {
+	f := Feature{Name: "maximize", DisplayName: "Maximize", HotkeyDesc: "Ctrl + Alt + ENTER key"}
+	if got, want := featureMenuTitle(f, nil), "Maximize (Ctrl + Alt + ENTER key)"; got != want {
+		t.Errorf("featureMenuTitle = %q, want %q", got, want)
+	}
+	failed := []HotKey{
+		{mod: MOD_CONTROL | MOD_ALT, vk: 0x0D, bindFeature: "maximize"},
+		// failures of other features, or with arguments, don't count
+		{mod: MOD_CONTROL | MOD_ALT, vk: 'M', bindFeature: "minimize"},
+		{mod: MOD_CONTROL | MOD_ALT, vk: '2', bindFeature: "maximize", hasArgs: true},
+	}
+	if got, want := featureMenuTitle(f, failed), "Maximize (Ctrl + Alt + ENTER key) ⚠ Ctrl + Alt + ENTER in use"; got != want {
+		t.Errorf("featureMenuTitle = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// The keyboard hook fallback. A hotkey another program has registered is
+// caught by a low-level keyboard hook instead when HotkeysConfig.KeyboardHook
+// is set. Like the mouse hook, on a match it swallows the key and posts
+// WM_HOTKEY with the binding's id to the thread running msgLoop.
+
+// maskVK is an unassigned key sent after a swallowed key or click, so that
+// releasing Win or Alt alone afterwards doesn't open the Start menu or the
+// menu bar.
+const maskVK = 0xE8
+
+// maskModifierRelease sends maskVK if mod holds Win or Alt, see maskVK.
+// The keyboard hook, mouse bindings and dragging all use it.
+func maskModifierRelease(mod int) {
+	if mod&(MOD_WIN|MOD_ALT) == 0 {
+		return
+	}
+	w32.SendInput(
+		w32.KeyboardInput(w32.KEYBDINPUT{Vk: maskVK}),
+		w32.KeyboardInput(w32.KEYBDINPUT{Vk: maskVK, Flags: w32.KEYEVENTF_KEYUP}))
+}
+
+var (
+	keyHook       w32.HHOOK
+	keyHookThread uint32
+	// set once installing the hook failed, so it's reported only once
+	keyHookFailed   bool
+	keyHookBindings = make(map[int]HotKey)
+	// keys whose press was swallowed; their repeats and release are
+	// swallowed too
+	swallowedKeys = make(map[int]bool)
+)
+
+func keyHookProc(nCode int, wParam w32.WPARAM, lParam w32.LPARAM) w32.LRESULT {
+	if nCode == 0 /* HC_ACTION */ {
+		info := *(**w32.KBDLLHOOKSTRUCT)(unsafe.Pointer(&lParam))
+		vk := int(info.VkCode)
+		switch wParam {
+		case w32.WM_KEYDOWN, w32.WM_SYSKEYDOWN:
+			if swallowedKeys[vk] {
+				// auto-repeat, like MOD_NOREPEAT
+				return 1
+			}
+			mod := modifierState(isKeyDown)
+			if hk, found := findHookBinding(keyHookBindings, vk, mod); found {
+				swallowedKeys[vk] = true
+				maskModifierRelease(mod)
+				// don't run the feature inside the hook, which has to return quickly
+				w32ex.PostThreadMessage(keyHookThread, w32.WM_HOTKEY, uintptr(hk.id), 0)
+				return 1
+			}
+		case w32.WM_KEYUP, w32.WM_SYSKEYUP:
+			if swallowedKeys[vk] {
+				delete(swallowedKeys, vk)
+				return 1
+			}
+		}
+	}
+	return w32.CallNextHookEx(keyHook, nCode, wParam, lParam)
+}
+
+// registerHookedHotKey catches h with the keyboard hook, installing it on
+// the calling thread, which must be the thread running msgLoop.
+func registerHookedHotKey(h HotKey) bool {
+	if isMouseKey(h.vk) {
+		return false
+	}
+	if dup, ok := findHookBinding(keyHookBindings, h.vk, h.mod&^MOD_NOREPEAT); ok {
+		fmt.Printf("warn: %s is already bound to %s\n", h.Describe(), dup.bindFeature)
+		return false
+	}
+	if keyHook == 0 {
+		if keyHookFailed {
+			return false
+		}
+		keyHookThread = w32ex.GetCurrentThreadId()
+		keyHook = w32.SetWindowsHookEx(w32.WH_KEYBOARD_LL, keyHookProc, w32.GetModuleHandle(""), 0)
+		if keyHook == 0 {
+			fmt.Printf("warn: failed to install keyboard hook: %d\n", w32.GetLastError())
+			keyHookFailed = true
+			return false
+		}
+	}
+	keyHookBindings[h.id] = h
+	hotkeyRegistrations[h.id] = &h
+	return true
+}
+
+// uninstallKeyHook removes the keyboard hook on exit.
+func uninstallKeyHook() {
+	if keyHook != 0 {
+		w32.UnhookWindowsHookEx(keyHook)
+		keyHook = 0
+	}
+}
+
+// unregisterHookedHotKey stops catching h, reporting whether it was
+// caught by the hook.
+func unregisterHookedHotKey(h HotKey) bool {
+	if _, ok := keyHookBindings[h.id]; !ok {
+		return false
+	}
+	delete(keyHookBindings, h.id)
+	delete(hotkeyRegistrations, h.id)
+	return true
+}
@@ -9,6 +9,13 @@ const (
 	MOD_WIN      = 0x0008
 )
 
+// Mouse triggers. Buttons use their virtual-key codes (VK_LBUTTON etc.);
+// the wheel has none, so it gets codes above the virtual-key range.
+const (
+	VK_WHEEL_UP   = 0x100
+	VK_WHEEL_DOWN = 0x101
+)
+
 var modKeyNames = map[int]string{
 	MOD_ALT:     "Alt",
 	MOD_CONTROL: "Ctrl",
@@ -182,4 +189,7 @@ var keyNames = map[int]string{
 	0xFB: `Zoom key`,
 	0xFD: `PA1 key`,
 	0xFE: `Clear key`,
+
+	VK_WHEEL_UP:   `Wheel up`,
+	VK_WHEEL_DOWN: `Wheel down`,
 }
@@ -0,0 +1,125 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gonutz/w32/v2"
+)
+
+// leaderKey identifies a second-stage key of the leader mode.
+type leaderKey struct {
+	mod, vk int32
+}
+
+// leaderMachine tracks the leader-key mode: idle until the leader hotkey
+// is pressed, then waiting for one of the second-stage keys until the
+// deadline passes. It holds no Win32 state so it can be tested directly;
+// hotkey.go does the registration work around it.
+type leaderMachine struct {
+	bindings map[leaderKey]KeyBinding
+	timeout  time.Duration
+	active   bool
+	deadline time.Time
+}
+
+func newLeaderMachine(keys []KeyBinding, timeout time.Duration) *leaderMachine {
+	l := &leaderMachine{
+		bindings: make(map[leaderKey]KeyBinding),
+		timeout:  timeout,
+	}
+	for _, kb := range keys {
+		if kb.KeyCode == 0 || kb.BindFeature == "" {
+			continue
+		}
+		l.bindings[leaderKey{kb.CombinedMod, kb.KeyCode}] = kb
+	}
+	return l
+}
+
+// Keys returns the second-stage keys in a stable order.
+func (l *leaderMachine) Keys() []leaderKey {
+	var keys []leaderKey
+	for k := range l.bindings {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].vk != keys[j].vk {
+			return keys[i].vk < keys[j].vk
+		}
+		return keys[i].mod < keys[j].mod
+	})
+	return keys
+}
+
+func (l *leaderMachine) Active() bool { return l.active }
+
+// Start enters the waiting state. It returns false when there is nothing
+// to wait for, or when the mode is already active.
+func (l *leaderMachine) Start(now time.Time) bool {
+	if l.active || len(l.bindings) == 0 {
+		return false
+	}
+	l.active = true
+	l.deadline = now.Add(l.timeout)
+	return true
+}
+
+// Press handles a second-stage key. Any press leaves the mode; the bound
+// binding is returned only if the key is known and the deadline has not
+// passed. Escape always cancels.
+func (l *leaderMachine) Press(k leaderKey, now time.Time) (KeyBinding, bool) {
+	if !l.active {
+		return KeyBinding{}, false
+	}
+	l.active = false
+	if k.vk == w32.VK_ESCAPE && k.mod == 0 || now.After(l.deadline) {
+		return KeyBinding{}, false
+	}
+	kb, ok := l.bindings[k]
+	return kb, ok
+}
+
+// Expire leaves the mode if the deadline has passed and reports whether
+// it did.
+func (l *leaderMachine) Expire(now time.Time) bool {
+	if !l.active || now.Before(l.deadline) {
+		return false
+	}
+	l.active = false
+	return true
+}
+
+// Cancel leaves the mode unconditionally.
+func (l *leaderMachine) Cancel() { l.active = false }
+
+// Hint renders the available keys, one per line, for the on-screen hint.
+func (l *leaderMachine) Hint() string {
+	var lines []string
+	for _, k := range l.Keys() {
+		feature := l.bindings[k].BindFeature
+		name := featureDisplayNames[feature]
+		if name == "" {
+			name = feature
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%s", HotKey{mod: int(k.mod), vk: int(k.vk)}.ShortDescribe(), name))
+	}
+	lines = append(lines, "Esc\tCancel")
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func testLeaderMachine() *leaderMachine {
+	return newLeaderMachine([]KeyBinding{
+		{KeyCode: 'L', BindFeature: "moveToLeft"},
+		{KeyCode: 'R', BindFeature: "moveToRight"},
+		{KeyCode: '1', CombinedMod: MOD_SHIFT, BindFeature: "moveToTopLeft"},
+		{KeyCode: 0, BindFeature: "maximize"}, // unparsed key, ignored
+	}, time.Second)
+}
+
+func TestLeaderMachinePress(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := testLeaderMachine()
+	if l.Active() {
+		t.Fatal("new leaderMachine should be idle")
+	}
+	if !l.Start(now) {
+		t.Fatal("Start() = false, want true")
+	}
+	if l.Start(now) {
+		t.Error("Start() while active = true, want false")
+	}
+	kb, ok := l.Press(leaderKey{vk: 'R'}, now.Add(500*time.Millisecond))
+	if !ok || kb.BindFeature != "moveToRight" {
+		t.Errorf("Press(R) = %q, %v, want moveToRight, true", kb.BindFeature, ok)
+	}
+	if l.Active() {
+		t.Error("leaderMachine should be idle after a press")
+	}
+	if _, ok := l.Press(leaderKey{vk: 'L'}, now); ok {
+		t.Error("Press() while idle should not return a feature")
+	}
+}
+
+func TestLeaderMachineModifiers(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := testLeaderMachine()
+	l.Start(now)
+	if _, ok := l.Press(leaderKey{vk: '1'}, now); ok {
+		t.Error("Press(1) without Shift should not match Shift+1")
+	}
+	l.Start(now)
+	if kb, ok := l.Press(leaderKey{mod: MOD_SHIFT, vk: '1'}, now); !ok || kb.BindFeature != "moveToTopLeft" {
+		t.Errorf("Press(Shift+1) = %q, %v, want moveToTopLeft, true", kb.BindFeature, ok)
+	}
+}
+
+func TestLeaderMachineCancel(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := testLeaderMachine()
+
+	l.Start(now)
+	if _, ok := l.Press(leaderKey{vk: w32.VK_ESCAPE}, now); ok {
+		t.Error("Escape should cancel")
+	}
+	if l.Active() {
+		t.Error("leaderMachine should be idle after Escape")
+	}
+
+	l.Start(now)
+	if _, ok := l.Press(leaderKey{vk: 'Z'}, now); ok {
+		t.Error("unbound key should cancel")
+	}
+	if l.Active() {
+		t.Error("leaderMachine should be idle after an unbound key")
+	}
+}
+
+func TestLeaderMachineTimeout(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := testLeaderMachine()
+	l.Start(now)
+	if l.Expire(now.Add(999 * time.Millisecond)) {
+		t.Error("Expire() before the deadline = true, want false")
+	}
+	if !l.Active() {
+		t.Error("leaderMachine should still be active before the deadline")
+	}
+	if !l.Expire(now.Add(time.Second)) {
+		t.Error("Expire() at the deadline = false, want true")
+	}
+	if l.Active() {
+		t.Error("leaderMachine should be idle after expiring")
+	}
+
+	// a press that arrives late (e.g. queued behind the timer) is ignored
+	l.Start(now)
+	if _, ok := l.Press(leaderKey{vk: 'L'}, now.Add(2*time.Second)); ok {
+		t.Error("Press() after the deadline should not return a feature")
+	}
+}
+
+func TestLeaderMachineNoKeys(t *testing.T) {
+	l := newLeaderMachine(nil, time.Second)
+	if l.Start(time.Now()) {
+		t.Error("Start() without keys = true, want false")
+	}
+}
+
+func TestLeaderMachineHint(t *testing.T) {
+	l := testLeaderMachine()
+	keys := l.Keys()
+	if len(keys) != 3 {
+		t.Fatalf("Keys() returned %d keys, want 3", len(keys))
+	}
+	if keys[0].vk != '1' || keys[1].vk != 'L' || keys[2].vk != 'R' {
+		t.Errorf("Keys() = %v, want sorted by key code", keys)
+	}
+	hint := l.Hint()
+	for _, want := range []string{"Shift + 1\tTop-Left corner", "L\tLeft half", "R\tRight half", "Esc\tCancel"} {
+		if !strings.Contains(hint, want) {
+			t.Errorf("Hint() = %q, missing %q", hint, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Linked edges. When enabled, resizing a window that shares an edge with
+// another window moves that window's edge along, so the pair stays flush
+// instead of overlapping. All windows are moved in one DeferWindowPos
+// batch.
+
+var linkedEdgesConfig LinkedEdgesConfig
+
+// linkEdges returns the frame to resize from to, and the new frames of
+// the windows in others that shared an edge, within tolerance, with from
+// and have to follow it. An edge that would push a neighbor below minSize
+// stops where the neighbor keeps minSize instead of overlapping it;
+// windows that would still end up narrower or shorter than minSize are
+// left alone. The map is keyed by indices into others.
+func linkEdges(from, to w32.RECT, others []w32.RECT, tolerance, minSize int32) (w32.RECT, map[int]w32.RECT) {
+	near := func(a, b int32) bool { return a-b <= tolerance && b-a <= tolerance }
+	overlaps := func(o w32.RECT) (bool, bool) {
+		return o.Top < from.Bottom && from.Top < o.Bottom, o.Left < from.Right && from.Left < o.Right
+	}
+	for _, o := range others {
+		overlapV, overlapH := overlaps(o)
+		if overlapV && to.Right > from.Right && near(from.Right, o.Left) {
+			to.Right = min(to.Right, max(from.Right, o.Right-minSize))
+		}
+		if overlapV && to.Left < from.Left && near(from.Left, o.Right) {
+			to.Left = max(to.Left, min(from.Left, o.Left+minSize))
+		}
+		if overlapH && to.Bottom > from.Bottom && near(from.Bottom, o.Top) {
+			to.Bottom = min(to.Bottom, max(from.Bottom, o.Bottom-minSize))
+		}
+		if overlapH && to.Top < from.Top && near(from.Top, o.Bottom) {
+			to.Top = max(to.Top, min(from.Top, o.Top+minSize))
+		}
+	}
+
+	result := make(map[int]w32.RECT)
+	for i, o := range others {
+		r := o
+		overlapV, overlapH := overlaps(o)
+		if overlapV && to.Right != from.Right && near(from.Right, o.Left) {
+			r.Left = to.Right
+		}
+		if overlapV && to.Left != from.Left && near(from.Left, o.Right) {
+			r.Right = to.Left
+		}
+		if overlapH && to.Bottom != from.Bottom && near(from.Bottom, o.Top) {
+			r.Top = to.Bottom
+		}
+		if overlapH && to.Top != from.Top && near(from.Top, o.Bottom) {
+			r.Bottom = to.Top
+		}
+		if r != o && r.Width() >= minSize && r.Height() >= minSize {
+			result[i] = r
+		}
+	}
+	return to, result
+}
+
+// resizeLinked is resize for features that change the size of a window in
+// place. With linked edges on, windows sharing a moved edge follow it.
+func resizeLinked(hwnd w32.HWND, f resizeFunc) (bool, error) {
+	if !linkedEdgesConfig.Enabled || !isZonableWindow(hwnd) || w32ex.IsZoomed(hwnd) {
+		return resize(hwnd, f)
+	}
+	mon := w32.MonitorFromWindow(hwnd, w32.MONITOR_DEFAULTTONEAREST)
+	var monInfo w32.MONITORINFO
+	if !w32.GetMonitorInfo(mon, &monInfo) {
+		return false, fmt.Errorf("failed to GetMonitorInfo:%d", w32.GetLastError())
+	}
+	from, ok := windowFrame(hwnd)
+	if !ok {
+		return false, fmt.Errorf("failed to DwmGetWindowAttributeEXTENDED_FRAME_BOUNDS:%d", w32.GetLastError())
+	}
+	windows, frames := neighborWindows(hwnd)
+	to, linked := linkEdges(from, f(monInfo.RcWork, from), frames, linkedEdgesConfig.Tolerance, minWindowSize)
+	if len(linked) == 0 {
+		// to may have been stopped short of a neighbor
+		return resize(hwnd, func(w32.RECT, w32.RECT) w32.RECT { return to })
+	}
+
+	target, ok := windowRectForFrame(hwnd, to)
+	if !ok {
+		return false, fmt.Errorf("failed to get the window rect of 0x%x:%d", hwnd, w32.GetLastError())
+	}
+	moves := map[w32.HWND]w32.RECT{hwnd: target}
+	for i, frame := range linked {
+		if r, ok := windowRectForFrame(windows[i], frame); ok {
+			moves[windows[i]] = r
+		}
+	}
+	hdwp := w32ex.BeginDeferWindowPos(len(moves))
+	for w, r := range moves {
+		fmt.Printf("> linked resize 0x%x to %#v\n", w, r)
+		hdwp = w32ex.DeferWindowPos(hdwp, w, 0, int(r.Left), int(r.Top), int(r.Width()), int(r.Height()),
+			w32.SWP_NOZORDER|w32.SWP_NOACTIVATE)
+		if hdwp == 0 {
+			return false, fmt.Errorf("failed to DeferWindowPos:%d", w32.GetLastError())
+		}
+	}
+	lastResized = hwnd
+	if !w32ex.EndDeferWindowPos(hdwp) {
+		return false, fmt.Errorf("failed to EndDeferWindowPos:%d", w32.GetLastError())
+	}
+	return true, nil
+}
+
+// windowRectForFrame converts a visible frame of hwnd to the window rect
+// that SetWindowPos expects, adding back the invisible borders.
+func windowRectForFrame(hwnd w32.HWND, frame w32.RECT) (w32.RECT, bool) {
+	rect := w32.GetWindowRect(hwnd)
+	ok, cur := w32.DwmGetWindowAttributeEXTENDED_FRAME_BOUNDS(hwnd)
+	if rect == nil || !ok {
+		return w32.RECT{}, false
+	}
+	return w32.RECT{
+		Left:   frame.Left - (cur.Left - rect.Left),
+		Top:    frame.Top - (cur.Top - rect.Top),
+		Right:  frame.Right + (rect.Right - cur.Right),
+		Bottom: frame.Bottom + (rect.Bottom - cur.Bottom),
+	}, true
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestLinkEdges(t *testing.T) {
+	left := rect(0, 0, 960, 1040)
+	right := rect(960, 0, 1920, 1040)
+	below := rect(0, 1040, 960, 1400)
+	far := rect(2000, 0, 2500, 500)
+	others := []w32.RECT{right, below, far}
+
+	// growing the left half to the right pushes the right half's left edge
+	_, got := linkEdges(left, rect(0, 0, 1060, 1040), others, 8, 100)
+	want := map[int]w32.RECT{0: rect(1060, 0, 1920, 1040)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grow right = %v, want %v", got, want)
+	}
+
+	// shrinking it pulls the edge back
+	_, got = linkEdges(left, rect(0, 0, 900, 1040), others, 8, 100)
+	want = map[int]w32.RECT{0: rect(900, 0, 1920, 1040)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shrink = %v, want %v", got, want)
+	}
+
+	// growing all edges moves the right and bottom neighbors
+	_, got = linkEdges(left, rect(0, 0, 1000, 1100), others, 8, 100)
+	want = map[int]w32.RECT{0: rect(1000, 0, 1920, 1040), 1: rect(0, 1100, 960, 1400)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grow both = %v, want %v", got, want)
+	}
+}
+
+func TestLinkEdgesTolerance(t *testing.T) {
+	left := rect(0, 0, 960, 1040)
+	gap := []w32.RECT{rect(967, 0, 1920, 1040)} // 7px apart, e.g. after rounding
+	if _, got := linkEdges(left, rect(0, 0, 1000, 1040), gap, 8, 100); len(got) != 1 {
+		t.Errorf("neighbor within tolerance not linked: %v", got)
+	}
+	if _, got := linkEdges(left, rect(0, 0, 1000, 1040), gap, 5, 100); len(got) != 0 {
+		t.Errorf("neighbor beyond tolerance linked: %v", got)
+	}
+}
+
+func TestLinkEdgesSkips(t *testing.T) {
+	left := rect(0, 0, 960, 500)
+	cases := []struct {
+		name  string
+		other w32.RECT
+		new   w32.RECT
+	}{
+		{"no shared span", rect(960, 600, 1920, 1040), rect(0, 0, 1060, 500)},
+		{"edge did not move", rect(960, 0, 1920, 500), rect(0, 0, 960, 600)},
+	}
+	for _, c := range cases {
+		if _, got := linkEdges(left, c.new, []w32.RECT{c.other}, 8, 100); len(got) != 0 {
+			t.Errorf("%s: got %v, want nothing linked", c.name, got)
+		}
+	}
+}
+
+func TestLinkEdgesStopsAtMinSize(t *testing.T) {
+	left := rect(0, 0, 960, 500)
+	cases := []struct {
+		name       string
+		other, to  w32.RECT
+		wantTo     w32.RECT
+		wantLinked map[int]w32.RECT
+	}{
+		{"growing into a narrow neighbor", rect(960, 0, 1100, 500), rect(0, 0, 1050, 500),
+			rect(0, 0, 1000, 500), map[int]w32.RECT{0: rect(1000, 0, 1100, 500)}},
+		{"neighbor already at the minimum", rect(960, 0, 1060, 500), rect(0, 0, 1050, 500),
+			rect(0, 0, 960, 500), map[int]w32.RECT{}},
+		{"other edges still move", rect(960, 0, 1060, 500), rect(0, 0, 1050, 550),
+			rect(0, 0, 960, 550), map[int]w32.RECT{}},
+		{"growing away from it", rect(960, 0, 1100, 500), rect(0, 0, 960, 600),
+			rect(0, 0, 960, 600), map[int]w32.RECT{}},
+	}
+	for _, c := range cases {
+		to, linked := linkEdges(left, c.to, []w32.RECT{c.other}, 8, 100)
+		if to != c.wantTo || !reflect.DeepEqual(linked, c.wantLinked) {
+			t.Errorf("%s: got %+v, %v; want %+v, %v", c.name, to, linked, c.wantTo, c.wantLinked)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/getlantern/systray"
@@ -45,6 +46,7 @@ var help *bool
 var action *string
 var loadTray *bool
 var settingsWindow *bool
+var printKeymap *string
 
 const currentVersion = "v1.0.4"
 
@@ -53,17 +55,12 @@ type Feature struct {
 	DisplayName string
 	Callback    func()
 	HotkeyDesc  string
+	// Submenu of the tray menu to list the feature under, if any.
+	Submenu string
 }
 
 var features []Feature
 
-type FeatureDefinition struct {
-	DisplayName string
-	Callback    func()
-}
-
-var featureDefinitions map[string]FeatureDefinition
-
 // Static map of feature display names for settings UI
 var featureDisplayNames = map[string]string{
 	"moveToTop":         "Top half",
@@ -87,9 +84,275 @@ var featureDisplayNames = map[string]string{
 	"nextDisplay":       "Next Display",
 	"prevDisplay":       "Previous Display",
 	"toggleAlwaysOnTop": "Toggle Always On Top",
+	"swapWithNext":      "Swap with Next Window",
+	"swapLeft":          "Swap Left",
+	"swapRight":         "Swap Right",
+	"swapUp":            "Swap Up",
+	"swapDown":          "Swap Down",
+	"focusLeft":         "Focus Left",
+	"focusRight":        "Focus Right",
+	"focusUp":           "Focus Up",
+	"focusDown":         "Focus Down",
+	"growLeft":          "Grow Left Edge",
+	"growRight":         "Grow Right Edge",
+	"growTop":           "Grow Top Edge",
+	"growBottom":        "Grow Bottom Edge",
+	"shrinkLeft":        "Shrink Left Edge",
+	"shrinkRight":       "Shrink Right Edge",
+	"shrinkTop":         "Shrink Top Edge",
+	"shrinkBottom":      "Shrink Bottom Edge",
+	"tileAll":           "Tile All",
+	"tileAllVertical":   "Tile All Side by Side",
+	"tileAllHorizontal": "Tile All Stacked",
+	"cascadeAll":        "Cascade All",
+	"undo":              "Undo Arrange",
+	"leader":            "Leader Key",
+	"adjust":            "Adjust Mode",
+	"toggleTiling":      "Toggle Tiling",
+	"cycleTilingLayout": "Next Tiling Layout",
+	"promoteToMaster":   "Promote to Master",
+	"swapTileNext":      "Swap with Next Tile",
+	"swapTilePrev":      "Swap with Previous Tile",
+	"growMaster":        "Grow Master",
+	"shrinkMaster":      "Shrink Master",
+	"moveToNextDesktop": "Move to Next Desktop",
+	"moveToPrevDesktop": "Move to Previous Desktop",
+
+	"minimize":            "Minimize",
+	"minimizeOthers":      "Minimize Others",
+	"restoreAllMinimized": "Restore All Minimized",
+	"hideToTray":          "Hide to Tray",
+
+	"toggleTransparency": "Toggle Transparency",
+	"opacityUp":          "Increase Opacity",
+	"opacityDown":        "Decrease Opacity",
+	"toggleClickThrough": "Toggle Click-Through",
+	"pictureInPicture":   "Picture in Picture",
+	"windowPicker":       "Window Picker",
+	"commandPalette":     "Command Palette",
+	"cheatSheet":         "Hotkey Cheat Sheet",
+
+	"centerHalf":              "Center Half",
+	"firstThird":              "First Third",
+	"centerThird":             "Center Third",
+	"lastThird":               "Last Third",
+	"firstTwoThirds":          "First Two Thirds",
+	"centerTwoThirds":         "Center Two Thirds",
+	"lastTwoThirds":           "Last Two Thirds",
+	"firstFourth":             "First Fourth",
+	"secondFourth":            "Second Fourth",
+	"thirdFourth":             "Third Fourth",
+	"lastFourth":              "Last Fourth",
+	"firstThreeFourths":       "First Three Fourths",
+	"centerThreeFourths":      "Center Three Fourths",
+	"lastThreeFourths":        "Last Three Fourths",
+	"topLeftSixth":            "Top Left Sixth",
+	"topCenterSixth":          "Top Center Sixth",
+	"topRightSixth":           "Top Right Sixth",
+	"bottomLeftSixth":         "Bottom Left Sixth",
+	"bottomCenterSixth":       "Bottom Center Sixth",
+	"bottomRightSixth":        "Bottom Right Sixth",
+	"topLeftEighth":           "Top Left Eighth",
+	"topCenterLeftEighth":     "Top Center Left Eighth",
+	"topCenterRightEighth":    "Top Center Right Eighth",
+	"topRightEighth":          "Top Right Eighth",
+	"bottomLeftEighth":        "Bottom Left Eighth",
+	"bottomCenterLeftEighth":  "Bottom Center Left Eighth",
+	"bottomCenterRightEighth": "Bottom Center Right Eighth",
+	"bottomRightEighth":       "Bottom Right Eighth",
+
+	"moveToDisplay": "Move to Display",
+	"moveToDesktop": "Move to Desktop",
+	"resizeBy":      "Resize By",
+	"moveBy":        "Move By",
+}
+
+// registerFeatures fills featureRegistry with every built-in feature.
+// Size presets are added once the configuration is loaded.
+func registerFeatures() {
+	edgeFuncs := [][]resizeFunc{
+		{leftHalf, leftTwoThirds, leftOneThirds},
+		{rightHalf, rightTwoThirds, rightOneThirds},
+		{topHalf, topTwoThirds, topOneThirds},
+		{bottomHalf, bottomTwoThirds, bottomOneThirds}}
+	edgeFuncTurn := make([]int, len(edgeFuncs))
+	cornerFuncs := [][]resizeFunc{
+		{topLeftHalf, topLeftTwoThirds, topLeftOneThirds},
+		{topRightHalf, topRightTwoThirds, topRightOneThirds},
+		{bottomLeftHalf, bottomLeftTwoThirds, bottomLeftOneThirds},
+		{bottomRightHalf, bottomRightTwoThirds, bottomRightOneThirds}}
+	cornerFuncTurn := make([]int, len(cornerFuncs))
+
+	cycleFuncs := func(funcs [][]resizeFunc, turns *[]int, i int) {
+		hwnd := getTargetWindow()
+		if hwnd == 0 {
+			fmt.Println("foreground window is NULL")
+			return
+		}
+		if lastResized != hwnd {
+			*turns = make([]int, len(edgeFuncs)) // reset
+		}
+		if _, err := resize(hwnd, funcs[i][(*turns)[i]%len(funcs[i])]); err != nil {
+			fmt.Printf("warn: resize: %v\n", err)
+			return
+		}
+		(*turns)[i]++
+		for j := 0; j < len(*turns); j++ {
+			if j != i {
+				(*turns)[j] = 0
+			}
+		}
+	}
+
+	cycleEdgeFuncs := func(i int) { cycleFuncs(edgeFuncs, &edgeFuncTurn, i) }
+	cycleCornerFuncs := func(i int) { cycleFuncs(cornerFuncs, &cornerFuncTurn, i) }
+
+	// Register all available features. Most take no arguments; the rest
+	// build their callback from the arguments given in config.yaml.
+	featureRegistry = map[string]featureRegistration{
+		"moveToTop": simpleFeature("Top half", func() { cycleEdgeFuncs(2) }),
+		"pushToTop": simpleFeature("Push to Top", func() {
+			if _, err := resize(getTargetWindow(), pushTop); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"moveToBottom": simpleFeature("Bottom half", func() { cycleEdgeFuncs(3) }),
+		"pushToBottom": simpleFeature("Push to Bottom", func() {
+			if _, err := resize(getTargetWindow(), pushBottom); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"moveToLeft": simpleFeature("Left half", func() { cycleEdgeFuncs(0) }),
+		"pushToLeft": simpleFeature("Push to Left", func() {
+			if _, err := resize(getTargetWindow(), pushLeft); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"moveToRight": simpleFeature("Right half", func() { cycleEdgeFuncs(1) }),
+		"pushToRight": simpleFeature("Push to Right", func() {
+			if _, err := resize(getTargetWindow(), pushRight); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"moveToTopLeft":     simpleFeature("Top-Left corner", func() { cycleCornerFuncs(0) }),
+		"moveToTopRight":    simpleFeature("Top-Right corner", func() { cycleCornerFuncs(1) }),
+		"moveToBottomLeft":  simpleFeature("Bottom-Left corner", func() { cycleCornerFuncs(2) }),
+		"moveToBottomRight": simpleFeature("Bottom-Right corner", func() { cycleCornerFuncs(3) }),
+
+		"maximize": simpleFeature("Maximize", func() {
+			lastResized = 0
+			if err := maximize(); err != nil {
+				fmt.Printf("warn: maximize: %v\n", err)
+			}
+		}),
+		"almostMaximize": simpleFeature("Almost Maximize", func() {
+			lastResized = 0
+			if _, err := resize(getTargetWindow(), func(disp, cur w32.RECT) w32.RECT {
+				return makeSmaller(disp, disp)
+			}); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"makeFullHeight": simpleFeature("Maximize Height", func() {
+			if _, err := resize(getTargetWindow(), maxHeight); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"makeLarger": simpleFeature("Larger", func() {
+			if _, err := resizeLinked(getTargetWindow(), stepResize(resizeConfig.Options, 1)); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"makeSmaller": simpleFeature("Smaller", func() {
+			if _, err := resizeLinked(getTargetWindow(), stepResize(resizeConfig.Options, -1)); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"moveToCenter": simpleFeature("Center", func() {
+			lastResized = 0
+			if _, err := resize(getTargetWindow(), center); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"nextDisplay": simpleFeature("Next Display", func() {
+			lastResized = 0
+			if _, err := resizeAcrossMonitor(getTargetWindow(), center, 1); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"prevDisplay": simpleFeature("Previous Display", func() {
+			lastResized = 0
+			if _, err := resizeAcrossMonitor(getTargetWindow(), center, -1); err != nil {
+				fmt.Printf("warn: resize: %v\n", err)
+			}
+		}),
+		"toggleAlwaysOnTop": simpleFeature("Toggle Always On Top", togglePin),
+		"swapWithNext": simpleFeature("Swap with Next Window", func() {
+			if err := swapWithNext(); err != nil {
+				fmt.Printf("warn: swapWithNext: %v\n", err)
+			}
+		}),
+		"swapLeft":          simpleFeature("Swap Left", swapFeature(dirLeft)),
+		"swapRight":         simpleFeature("Swap Right", swapFeature(dirRight)),
+		"swapUp":            simpleFeature("Swap Up", swapFeature(dirUp)),
+		"swapDown":          simpleFeature("Swap Down", swapFeature(dirDown)),
+		"focusLeft":         simpleFeature("Focus Left", focusFeature(dirLeft)),
+		"focusRight":        simpleFeature("Focus Right", focusFeature(dirRight)),
+		"focusUp":           simpleFeature("Focus Up", focusFeature(dirUp)),
+		"focusDown":         simpleFeature("Focus Down", focusFeature(dirDown)),
+		"growLeft":          simpleFeature("Grow Left Edge", edgeFeature(dirLeft, true)),
+		"growRight":         simpleFeature("Grow Right Edge", edgeFeature(dirRight, true)),
+		"growTop":           simpleFeature("Grow Top Edge", edgeFeature(dirUp, true)),
+		"growBottom":        simpleFeature("Grow Bottom Edge", edgeFeature(dirDown, true)),
+		"shrinkLeft":        simpleFeature("Shrink Left Edge", edgeFeature(dirLeft, false)),
+		"shrinkRight":       simpleFeature("Shrink Right Edge", edgeFeature(dirRight, false)),
+		"shrinkTop":         simpleFeature("Shrink Top Edge", edgeFeature(dirUp, false)),
+		"shrinkBottom":      simpleFeature("Shrink Bottom Edge", edgeFeature(dirDown, false)),
+		"tileAll":           simpleFeature("Tile All", tileAll),
+		"tileAllVertical":   simpleFeature("Tile All Side by Side", tileAllVertical),
+		"tileAllHorizontal": simpleFeature("Tile All Stacked", tileAllHorizontal),
+		"cascadeAll":        simpleFeature("Cascade All", cascadeAll),
+		"undo":              simpleFeature("Undo Arrange", undoArrange),
+		"leader":            simpleFeature("Leader Key", enterLeaderMode),
+		"adjust":            simpleFeature("Adjust Mode", enterAdjustMode),
+		"toggleTiling":      simpleFeature("Toggle Tiling", toggleTiling),
+		"cycleTilingLayout": simpleFeature("Next Tiling Layout", cycleTilingLayout),
+		"promoteToMaster":   simpleFeature("Promote to Master", promoteToMaster),
+		"swapTileNext":      simpleFeature("Swap with Next Tile", func() { swapTile(1) }),
+		"swapTilePrev":      simpleFeature("Swap with Previous Tile", func() { swapTile(-1) }),
+		"growMaster":        simpleFeature("Grow Master", func() { changeMasterPercent(tilingConfig.RatioStep) }),
+		"shrinkMaster":      simpleFeature("Shrink Master", func() { changeMasterPercent(-tilingConfig.RatioStep) }),
+
+		"moveToNextDesktop": simpleFeature("Move to Next Desktop", desktopFeature(1)),
+		"moveToPrevDesktop": simpleFeature("Move to Previous Desktop", desktopFeature(-1)),
+
+		"minimize":            simpleFeature("Minimize", minimize),
+		"minimizeOthers":      simpleFeature("Minimize Others", minimizeOthers),
+		"restoreAllMinimized": simpleFeature("Restore All Minimized", restoreAllMinimized),
+		"hideToTray":          simpleFeature("Hide to Tray", hideToTray),
+
+		"toggleTransparency": simpleFeature("Toggle Transparency", effectFeature("toggleTransparency", toggleTransparency)),
+		"opacityUp":          simpleFeature("Increase Opacity", effectFeature("opacityUp", opacityUp)),
+		"opacityDown":        simpleFeature("Decrease Opacity", effectFeature("opacityDown", opacityDown)),
+		"toggleClickThrough": simpleFeature("Toggle Click-Through", effectFeature("toggleClickThrough", toggleClickThrough)),
+		"pictureInPicture":   simpleFeature("Picture in Picture", togglePictureInPicture),
+		"windowPicker":       simpleFeature("Window Picker", showWindowPicker),
+		"commandPalette":     simpleFeature("Command Palette", showCommandPalette),
+		"cheatSheet":         simpleFeature("Hotkey Cheat Sheet", cheatSheet),
+
+		"moveToDisplay": {"Move to Display", newMoveToDisplayFeature},
+		"moveToDesktop": {"Move to Desktop", newMoveToDesktopFeature},
+		"resizeBy":      {"Resize By", newResizeByFeature},
+		"moveBy":        {"Move By", newMoveByFeature},
+	}
+	for _, l := range fractionLayouts {
+		featureRegistry[l.name] = simpleFeature(featureDisplayNames[l.name], layoutFeature(l.f))
+	}
 }
 
 func main() {
+	registerFeatures()
+
 	// Initialize flags with ContinueOnError to handle parsing errors
 	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
 
@@ -105,11 +368,12 @@ func main() {
 	debug = flag.Bool("debug", false, "enable debug mode (show console output)")
 	killAll = flag.Bool("killall", false, "kill all RectangleWinPlus instances and quit")
 	help = flag.Bool("help", false, "show this help message")
-	action = flag.String("action", "", "action to perform (moveToTop, moveToBottom, moveToLeft, moveToRight, moveToTopLeft, moveToTopRight, moveToBottomLeft, moveToBottomRight, maximize, almostMaximize, makeFullHeight, makeLarger, makeSmaller)")
+	action = flag.String("action", "", "action to perform: "+strings.Join(featureNames(), ", ")+", or "+sizePresetPrefix+"<name> for a size preset")
 	loadTray = flag.Bool("load_tray", true, "load tray icon")
 	version := flag.Bool("version", false, "show version information")
 	helpfull := flag.Bool("helpfull", false, "show detailed help message")
 	settingsWindow = flag.Bool("settings-window", false, "open settings window (internal use)")
+	printKeymap = flag.String("print-keymap", "", "print the configured hotkeys as markdown or html and exit")
 
 	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
 		if err != flag.ErrHelp {
@@ -161,6 +425,19 @@ func main() {
 		return
 	}
 
+	// The keymap goes to stdout, so it can be redirected to a file; the
+	// startup diagnostics below go to stderr instead.
+	var keymapOut *os.File
+	if *printKeymap != "" {
+		fixconsole.FixConsoleIfNeeded()
+		if _, err := formatKeymap(nil, *printKeymap); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		keymapOut = os.Stdout
+		os.Stdout = os.Stderr
+	}
+
 	runtime.LockOSThread() // since we bind hotkeys etc that need to dispatch their message here
 	if !w32ex.SetProcessDPIAware() {
 		panic("failed to set DPI aware")
@@ -183,171 +460,68 @@ func main() {
 		}
 	}()
 
-	edgeFuncs := [][]resizeFunc{
-		{leftHalf, leftTwoThirds, leftOneThirds},
-		{rightHalf, rightTwoThirds, rightOneThirds},
-		{topHalf, topTwoThirds, topOneThirds},
-		{bottomHalf, bottomTwoThirds, bottomOneThirds}}
-	edgeFuncTurn := make([]int, len(edgeFuncs))
-	cornerFuncs := [][]resizeFunc{
-		{topLeftHalf, topLeftTwoThirds, topLeftOneThirds},
-		{topRightHalf, topRightTwoThirds, topRightOneThirds},
-		{bottomLeftHalf, bottomLeftTwoThirds, bottomLeftOneThirds},
-		{bottomRightHalf, bottomRightTwoThirds, bottomRightOneThirds}}
-	cornerFuncTurn := make([]int, len(cornerFuncs))
-
-	cycleFuncs := func(funcs [][]resizeFunc, turns *[]int, i int) {
-		hwnd := getTargetWindow()
-		if hwnd == 0 {
-			fmt.Println("foreground window is NULL")
-			return
-		}
-		if lastResized != hwnd {
-			*turns = make([]int, len(edgeFuncs)) // reset
-		}
-		if _, err := resize(hwnd, funcs[i][(*turns)[i]%len(funcs[i])]); err != nil {
-			fmt.Printf("warn: resize: %v\n", err)
-			return
-		}
-		(*turns)[i]++
-		for j := 0; j < len(*turns); j++ {
-			if j != i {
-				(*turns)[j] = 0
-			}
-		}
-	}
-
-	cycleEdgeFuncs := func(i int) { cycleFuncs(edgeFuncs, &edgeFuncTurn, i) }
-	cycleCornerFuncs := func(i int) { cycleFuncs(cornerFuncs, &cornerFuncTurn, i) }
-
-	// Define all available features
-	featureMap := map[string]struct {
-		DisplayName string
-		Callback    func()
-	}{
-		"moveToTop": {"Top half", func() { cycleEdgeFuncs(2) }},
-		"pushToTop": {"Push to Top", func() {
-			if _, err := resize(getTargetWindow(), pushTop); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"moveToBottom": {"Bottom half", func() { cycleEdgeFuncs(3) }},
-		"pushToBottom": {"Push to Bottom", func() {
-			if _, err := resize(getTargetWindow(), pushBottom); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"moveToLeft": {"Left half", func() { cycleEdgeFuncs(0) }},
-		"pushToLeft": {"Push to Left", func() {
-			if _, err := resize(getTargetWindow(), pushLeft); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"moveToRight": {"Right half", func() { cycleEdgeFuncs(1) }},
-		"pushToRight": {"Push to Right", func() {
-			if _, err := resize(getTargetWindow(), pushRight); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"moveToTopLeft":     {"Top-Left corner", func() { cycleCornerFuncs(0) }},
-		"moveToTopRight":    {"Top-Right corner", func() { cycleCornerFuncs(1) }},
-		"moveToBottomLeft":  {"Bottom-Left corner", func() { cycleCornerFuncs(2) }},
-		"moveToBottomRight": {"Bottom-Right corner", func() { cycleCornerFuncs(3) }},
+	// load the configuration before --action, so size presets and the
+	// settings features read are in place for it as well
+	myConfig := fetchConfiguration()
+	fmt.Println(myConfig)
+	leader = newLeaderMachine(myConfig.Leader.Keys, time.Duration(myConfig.Leader.TimeoutMs)*time.Millisecond)
+	adjustConfig = myConfig.Adjust
+	linkedEdgesConfig = myConfig.LinkedEdges
+	resizeConfig = myConfig.Resize
+	desktops = windowsDesktops{}
+	perDesktopUndo = myConfig.VirtualDesktops.PerDesktopUndo
+	opacityConfig = myConfig.Opacity
+	pipConfig = myConfig.PictureInPicture
+	pickerConfig = myConfig.Picker
+	presetKeys := registerSizePresets(parseSizePresets(myConfig.SizePresets))
 
-		"maximize": {"Maximize", func() {
-			lastResized = 0
-			if err := maximize(); err != nil {
-				fmt.Printf("warn: maximize: %v\n", err)
-			}
-		}},
-		"almostMaximize": {"Almost Maximize", func() {
-			lastResized = 0
-			if _, err := resize(getTargetWindow(), func(disp, cur w32.RECT) w32.RECT {
-				return makeSmaller(disp, disp)
-			}); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"makeFullHeight": {"Maximize Height", func() {
-			if _, err := resize(getTargetWindow(), maxHeight); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"makeLarger": {"Larger", func() {
-			if _, err := resize(getTargetWindow(), makeLarger); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"makeSmaller": {"Smaller", func() {
-			if _, err := resize(getTargetWindow(), makeSmaller); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"moveToCenter": {"Center", func() {
-			lastResized = 0
-			if _, err := resize(getTargetWindow(), center); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"nextDisplay": {"Next Display", func() {
-			lastResized = 0
-			if _, err := resizeAcrossMonitor(getTargetWindow(), center, 1); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"prevDisplay": {"Previous Display", func() {
-			lastResized = 0
-			if _, err := resizeAcrossMonitor(getTargetWindow(), center, -1); err != nil {
-				fmt.Printf("warn: resize: %v\n", err)
-			}
-		}},
-		"toggleAlwaysOnTop": {"Toggle Always On Top", func() {
-			hwnd := getTargetWindow()
-			if err := toggleAlwaysOnTop(hwnd); err != nil {
-				fmt.Printf("warn: toggleAlwaysOnTop: %v\n", err)
-				return
-			}
-			fmt.Printf("> toggled always on top: %v\n", hwnd)
-		}},
-	}
 	if *action != "" {
-		if feature, ok := featureMap[*action]; ok {
-			feature.Callback()
-			fmt.Printf("%s Action completed successfully\n", *action)
-			os.Exit(0)
+		callback, err := newFeature(*action, nil)
+		if err != nil {
+			fmt.Printf("warn: %v\n", err)
+			os.Exit(1)
 		}
-		fmt.Printf("warn: unknown action: %s\n", *action)
-		os.Exit(1)
+		callback()
+		fmt.Printf("%s Action completed successfully\n", *action)
+		os.Exit(0)
 	}
 
 	if *helpfull {
 		fixconsole.FixConsoleIfNeeded()
 		flag.Usage()
 		fmt.Println("\nFeatures:")
-		for feature, value := range featureMap {
-			fmt.Printf("%s: %s\n", feature, value.DisplayName)
+		for _, feature := range featureNames() {
+			fmt.Printf("%s: %s\n", feature, featureRegistry[feature].DisplayName)
 		}
 		return
 	}
 
 	hks = []HotKey{}
 
-	myConfig := fetchConfiguration()
-	fmt.Println(myConfig)
 	// start from id 200
 	id := 200
 	for _, keyBinding := range myConfig.Keybindings {
-		if feature, ok := featureMap[keyBinding.BindFeature]; ok {
-			id += 1
-			hk := HotKey{
-				id:          id,
-				mod:         int(keyBinding.CombinedMod) | MOD_NOREPEAT,
-				vk:          int(keyBinding.KeyCode),
-				callback:    feature.Callback,
-				bindFeature: keyBinding.BindFeature,
-			}
-			hks = append(hks, hk)
+		callback, err := newFeature(keyBinding.BindFeature, keyBinding.Args)
+		if err != nil {
+			fmt.Printf("warn: %v\n", err)
+			continue
 		}
+		id += 1
+		hk := HotKey{
+			id:          id,
+			mod:         int(keyBinding.CombinedMod) | MOD_NOREPEAT,
+			vk:          int(keyBinding.KeyCode),
+			callback:    callback,
+			bindFeature: keyBinding.BindFeature,
+			hasArgs:     keyBinding.Args != nil,
+			args:        describeArgs(keyBinding.Args),
+		}
+		hks = append(hks, hk)
+	}
+	if *printKeymap != "" {
+		out, _ := formatKeymap(keymapGroups(hks), *printKeymap)
+		fmt.Fprint(keymapOut, out)
+		return
 	}
 	// Populate global features list with hotkey info
 	// Order matters for the menu
@@ -356,44 +530,95 @@ func main() {
 		"moveToLeft", "moveToRight", "moveToTop", "moveToBottom",
 		"moveToTopLeft", "moveToTopRight", "moveToBottomLeft", "moveToBottomRight",
 		"moveToCenter", "maximize", "almostMaximize", "makeLarger", "makeSmaller", "makeFullHeight",
-		"nextDisplay", "prevDisplay", "toggleAlwaysOnTop",
+		"nextDisplay", "prevDisplay", "moveToNextDesktop", "moveToPrevDesktop", "toggleAlwaysOnTop",
+		"minimize", "minimizeOthers", "restoreAllMinimized", "hideToTray",
+		"toggleTransparency", "opacityUp", "opacityDown", "toggleClickThrough", "pictureInPicture",
+		"windowPicker", "commandPalette", "cheatSheet",
+		"tileAll", "tileAllVertical", "tileAllHorizontal", "cascadeAll", "undo",
+		"swapWithNext", "swapLeft", "swapRight", "swapUp", "swapDown",
+		"toggleTiling", "cycleTilingLayout", "promoteToMaster", "growMaster", "shrinkMaster",
 		// pushTo series happen last, because they are less used, as aligned in Rectangle.
 		"pushToLeft", "pushToRight", "pushToTop", "pushToBottom",
 	}
+	orderedKeys = append(orderedKeys, presetKeys...)
+	submenus := map[string]string{}
+	for _, l := range fractionLayouts {
+		orderedKeys = append(orderedKeys, l.name)
+		submenus[l.name] = "More Layouts"
+	}
 
 	for _, key := range orderedKeys {
-		if val, ok := featureMap[key]; ok {
-			desc := ""
-			// Find if there is a hotkey for this feature
-			for _, hk := range hks {
-				if hk.bindFeature == key {
-					desc = hk.Describe()
-					break
-				}
+		val, ok := featureRegistry[key]
+		if !ok {
+			continue
+		}
+		callback, err := val.New(nil)
+		if err != nil {
+			// needs arguments, only reachable through a hotkey
+			continue
+		}
+		// List every hotkey bound to this feature without arguments
+		var descs []string
+		for _, hk := range hks {
+			if hk.bindFeature == key && !hk.hasArgs {
+				descs = append(descs, hk.Describe())
 			}
-			features = append(features, Feature{
-				Name:        key,
-				DisplayName: val.DisplayName,
-				Callback:    val.Callback,
-				HotkeyDesc:  desc,
-			})
 		}
+		features = append(features, Feature{
+			Name:        key,
+			DisplayName: val.DisplayName,
+			Callback:    callback,
+			HotkeyDesc:  strings.Join(descs, ", "),
+			Submenu:     submenus[key],
+		})
 	}
 
-	var failedHotKeys []HotKey
-	for _, hk := range hks {
-		if !RegisterHotKey(hk) {
-			failedHotKeys = append(failedHotKeys, hk)
+	var skipped []string
+	for i, hk := range hks {
+		if dup, ok := duplicateHotKey(hks[:i], hk); ok {
+			fmt.Printf("warn: %s (%s) is already bound to %s, skipping\n", hk.Describe(), hk.bindFeature, dup.bindFeature)
+			continue
+		}
+		if RegisterHotKey(hk) {
+			continue
 		}
+		if _, ok := hotkeyRegistrations[hk.id]; ok {
+			// its id is taken, which RegisterHotKey warned about
+			continue
+		}
+		if isMouseKey(hk.vk) {
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", hk.Describe(), mouseBindingProblem(hk)))
+			continue
+		}
+		if myConfig.Hotkeys.KeyboardHook && registerHookedHotKey(hk) {
+			fmt.Printf("hotkey in use, caught by the keyboard hook instead: %v\n", hk)
+			continue
+		}
+		failedHotKeys.set(hk, true)
 	}
-	if len(failedHotKeys) > 0 {
+	if failed := failedHotKeys.list(); len(failed) > 0 {
 		msg := "The following hotkey(s) are in use by another process:\n\n"
-		for _, hk := range failedHotKeys {
-			msg += "  - " + hk.Describe() + "\n"
+		for _, hk := range failed {
+			msg += "  - " + hk.Describe()
+			if use, ok := reservedHotkeyUse(int32(hk.mod), int32(hk.vk)); ok {
+				msg += " (reserved by Windows for " + use + ")"
+			}
+			msg += "\n"
 		}
 		msg += "\nTo use these hotkeys in RectangleWin Plus, close the other process using the key combination(s)."
+		msg += " They are marked in the tray menu and retried until they are free."
+		if s := myConfig.Hotkeys.RetrySeconds; s > 0 {
+			hotkeyRetryTimer = w32.SetTimer(0, 0, uint(s*1000), 0)
+		}
 		showMessageBox(msg)
 	}
+	if len(skipped) > 0 {
+		showMessageBox("The following mouse binding(s) could not be set up and were skipped:\n\n  - " +
+			strings.Join(skipped, "\n  - ") + "\n\nCheck the bindings in config.yaml.")
+	}
+	setupDrag(myConfig.Drag)
+	setupTiling(myConfig.Tiling)
+	setupPinned(myConfig.Pinned)
 
 	exitCh := make(chan os.Signal, 1)
 	signal.Notify(exitCh, os.Interrupt)
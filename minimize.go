@@ -0,0 +1,179 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Minimizing, restoring and hiding windows. hideToTray takes a window off
+// the screen and the taskbar; the tray menu lists it until it is shown
+// again, and quitting shows every hidden window.
+
+// othersOnMonitor returns the windows other than target on its monitor,
+// keeping their order.
+func othersOnMonitor(target w32.HWND, windows []w32.HWND, monitorOf func(w32.HWND) w32.HMONITOR) []w32.HWND {
+	mon := monitorOf(target)
+	var others []w32.HWND
+	for _, hwnd := range windows {
+		if hwnd != target && monitorOf(hwnd) == mon {
+			others = append(others, hwnd)
+		}
+	}
+	return others
+}
+
+func monitorOfWindow(hwnd w32.HWND) w32.HMONITOR {
+	return w32.MonitorFromWindow(hwnd, w32.MONITOR_DEFAULTTONEAREST)
+}
+
+// minimizedWindows lists the minimized zonable windows on the current
+// virtual desktop, in z-order from the topmost down.
+func minimizedWindows() []w32.HWND {
+	var windows []w32.HWND
+	w32.EnumWindows(func(hwnd w32.HWND) bool {
+		if isZonableWindow(hwnd) && w32ex.IsIconic(hwnd) && !w32ex.IsCloaked(hwnd) {
+			windows = append(windows, hwnd)
+		}
+		return true
+	})
+	return windows
+}
+
+func minimize() {
+	hwnd := getTargetWindow()
+	if hwnd == 0 {
+		fmt.Println("warn: minimize: no target window")
+		return
+	}
+	lastResized = 0
+	w32.ShowWindow(hwnd, w32.SW_MINIMIZE)
+}
+
+// minimizeOthers minimizes every other window on the monitor of the target
+// window, leaving it focused, like "show desktop" except for one window.
+func minimizeOthers() {
+	hwnd := getTargetWindow()
+	if hwnd == 0 {
+		fmt.Println("warn: minimizeOthers: no target window")
+		return
+	}
+	for _, other := range othersOnMonitor(hwnd, zonableWindows(), monitorOfWindow) {
+		w32.ShowWindow(other, w32.SW_SHOWMINNOACTIVE)
+	}
+}
+
+// restoreAllMinimized restores the minimized windows, bottom first so that
+// they keep their stacking order.
+func restoreAllMinimized() {
+	windows := minimizedWindows()
+	for i := len(windows) - 1; i >= 0; i-- {
+		w32.ShowWindow(windows[i], w32.SW_RESTORE)
+	}
+}
+
+type hiddenWindow struct {
+	hwnd  w32.HWND
+	title string
+}
+
+// hiddenList holds the windows hidden by hideToTray, oldest first.
+type hiddenList struct {
+	mu      sync.Mutex
+	windows []hiddenWindow
+}
+
+// add records w, unless its window is already hidden.
+func (l *hiddenList) add(w hiddenWindow) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, h := range l.windows {
+		if h.hwnd == w.hwnd {
+			return false
+		}
+	}
+	l.windows = append(l.windows, w)
+	return true
+}
+
+func (l *hiddenList) remove(hwnd w32.HWND) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, h := range l.windows {
+		if h.hwnd == hwnd {
+			l.windows = append(l.windows[:i], l.windows[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// list returns a copy of the hidden windows.
+func (l *hiddenList) list() []hiddenWindow {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]hiddenWindow(nil), l.windows...)
+}
+
+var hiddenWindows hiddenList
+
+// onHiddenWindowsChanged is called after a window is hidden or shown
+// again. The tray sets it to update its menu.
+var onHiddenWindowsChanged = func() {}
+
+func hideToTray() {
+	hwnd := getTargetWindow()
+	if err := hideWindow(hwnd); err != nil {
+		fmt.Printf("warn: hideToTray: %v\n", err)
+	}
+}
+
+func hideWindow(hwnd w32.HWND) error {
+	if !isZonableWindow(hwnd) {
+		return errors.New("no window to hide")
+	}
+	if !hiddenWindows.add(hiddenWindow{hwnd: hwnd, title: w32.GetWindowText(hwnd)}) {
+		return nil
+	}
+	lastResized = 0
+	w32.ShowWindow(hwnd, w32.SW_HIDE)
+	onHiddenWindowsChanged()
+	return nil
+}
+
+// unhideWindow shows a window hidden by hideToTray and brings it to the
+// front.
+func unhideWindow(hwnd w32.HWND) {
+	if !hiddenWindows.remove(hwnd) {
+		return
+	}
+	if w32.IsWindow(hwnd) {
+		w32.ShowWindow(hwnd, w32.SW_SHOW)
+		w32.SetForegroundWindow(hwnd)
+	}
+	onHiddenWindowsChanged()
+}
+
+// unhideAllWindows shows every window hidden by hideToTray.
+func unhideAllWindows() {
+	for _, w := range hiddenWindows.list() {
+		unhideWindow(w.hwnd)
+	}
+}
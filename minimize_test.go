@@ -0,0 +1,65 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestOthersOnMonitor(t *testing.T) {
+	monitors := map[w32.HWND]w32.HMONITOR{1: 10, 2: 20, 3: 10, 4: 10, 5: 20}
+	monitorOf := func(hwnd w32.HWND) w32.HMONITOR { return monitors[hwnd] }
+	windows := []w32.HWND{4, 1, 2, 3, 5}
+
+	if got, want := othersOnMonitor(1, windows, monitorOf), []w32.HWND{4, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("othersOnMonitor(1) = %v, want %v", got, want)
+	}
+	if got, want := othersOnMonitor(5, windows, monitorOf), []w32.HWND{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("othersOnMonitor(5) = %v, want %v", got, want)
+	}
+	// the target need not be among the windows, e.g. when minimized
+	if got, want := othersOnMonitor(6, windows, func(w32.HWND) w32.HMONITOR { return 10 }), windows; !reflect.DeepEqual(got, want) {
+		t.Errorf("othersOnMonitor(6) = %v, want %v", got, want)
+	}
+}
+
+func TestHiddenList(t *testing.T) {
+	var l hiddenList
+	if !l.add(hiddenWindow{1, "one"}) || !l.add(hiddenWindow{2, "two"}) {
+		t.Fatal("add of new windows failed")
+	}
+	if l.add(hiddenWindow{1, "renamed"}) {
+		t.Error("adding a hidden window again should be refused")
+	}
+	if got, want := l.list(), []hiddenWindow{{1, "one"}, {2, "two"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list = %v, want %v", got, want)
+	}
+
+	list := l.list()
+	list[0].title = "changed"
+	if l.list()[0].title != "one" {
+		t.Error("list should return a copy")
+	}
+
+	if !l.remove(1) || l.remove(1) || l.remove(3) {
+		t.Error("remove should succeed once for hidden windows only")
+	}
+	if got, want := l.list(), []hiddenWindow{{2, "two"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list after remove = %v, want %v", got, want)
+	}
+}
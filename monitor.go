@@ -32,6 +32,38 @@ func EnumMonitors(f func(d w32.HMONITOR) bool) bool {
 	return w32.EnumDisplayMonitors(0, nil, callback, 0)
 }
 
+// monitorAt returns the monitor at the given 0-based index, in the order
+// EnumDisplayMonitors reports them (the same order printMonitors uses).
+func monitorAt(index int) (w32.HMONITOR, bool) {
+	var found w32.HMONITOR
+	i := 0
+	EnumMonitors(func(d w32.HMONITOR) bool {
+		if i == index {
+			found = d
+			return false
+		}
+		i++
+		return true
+	})
+	return found, found != 0
+}
+
+// monitorIndex returns the 0-based index of mon in the order of monitorAt,
+// or -1.
+func monitorIndex(mon w32.HMONITOR) int {
+	found := -1
+	i := 0
+	EnumMonitors(func(d w32.HMONITOR) bool {
+		if d == mon {
+			found = i
+			return false
+		}
+		i++
+		return true
+	})
+	return found
+}
+
 func printMonitors() {
 	i := 0
 	EnumMonitors(func(d w32.HMONITOR) bool {
@@ -0,0 +1,233 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Mouse bindings. RegisterHotKey can't bind mouse buttons or the wheel, so
+// HotKeys whose vk is a mouse trigger are matched by a low-level mouse hook
+// instead. On a match the hook posts WM_HOTKEY with the binding's id to the
+// thread running msgLoop, which dispatches it like any other hotkey.
+
+var (
+	mouseHook       w32.HHOOK
+	mouseHookThread uint32
+	// mouse bindings plus modifier+drag, see drag.go
+	mouseHookUsers int
+	mouseBindings  = make(map[int]HotKey)
+	// buttons whose press was swallowed; their release is swallowed too so
+	// the window under the cursor never sees half a click
+	swallowedMouseUp = make(map[int]bool)
+)
+
+func isMouseKey(vk int) bool {
+	switch vk {
+	case w32.VK_LBUTTON, w32.VK_RBUTTON, w32.VK_MBUTTON, w32.VK_XBUTTON1, w32.VK_XBUTTON2,
+		VK_WHEEL_UP, VK_WHEEL_DOWN:
+		return true
+	}
+	return false
+}
+
+// validateMouseBinding rejects bindings that would take over ordinary
+// clicks: the left and right buttons need at least one modifier.
+func validateMouseBinding(mod, vk int) error {
+	if (vk == w32.VK_LBUTTON || vk == w32.VK_RBUTTON) && mod&^MOD_NOREPEAT == 0 {
+		return errors.New("left and right mouse buttons need a modifier")
+	}
+	return nil
+}
+
+// mouseBindingProblem tells why registering the mouse binding h failed.
+// Unlike a hotkey held by another process, retrying won't fix either
+// reason.
+func mouseBindingProblem(h HotKey) string {
+	if err := validateMouseBinding(h.mod, h.vk); err != nil {
+		return err.Error()
+	}
+	return "the mouse hook could not be installed"
+}
+
+// mouseTrigger maps a low-level mouse message to the trigger it represents.
+// down is false for button releases. The wheel only reports presses.
+func mouseTrigger(msg uint32, mouseData uint32) (vk int, down bool, ok bool) {
+	switch msg {
+	case w32.WM_LBUTTONDOWN:
+		return w32.VK_LBUTTON, true, true
+	case w32.WM_LBUTTONUP:
+		return w32.VK_LBUTTON, false, true
+	case w32.WM_RBUTTONDOWN:
+		return w32.VK_RBUTTON, true, true
+	case w32.WM_RBUTTONUP:
+		return w32.VK_RBUTTON, false, true
+	case w32.WM_MBUTTONDOWN:
+		return w32.VK_MBUTTON, true, true
+	case w32.WM_MBUTTONUP:
+		return w32.VK_MBUTTON, false, true
+	case w32.WM_XBUTTONDOWN, w32.WM_XBUTTONUP:
+		down = msg == w32.WM_XBUTTONDOWN
+		switch mouseData >> 16 {
+		case w32.XBUTTON1:
+			return w32.VK_XBUTTON1, down, true
+		case w32.XBUTTON2:
+			return w32.VK_XBUTTON2, down, true
+		}
+	case w32.WM_MOUSEWHEEL:
+		// the high word is a signed delta; positive is away from the user
+		if delta := int16(mouseData >> 16); delta > 0 {
+			return VK_WHEEL_UP, true, true
+		} else if delta < 0 {
+			return VK_WHEEL_DOWN, true, true
+		}
+	}
+	return 0, false, false
+}
+
+// modifierState returns the MOD_* flags for the modifier keys that isDown
+// reports as held.
+func modifierState(isDown func(vk int) bool) int {
+	mod := 0
+	if isDown(w32.VK_CONTROL) {
+		mod |= MOD_CONTROL
+	}
+	if isDown(w32.VK_MENU) {
+		mod |= MOD_ALT
+	}
+	if isDown(w32.VK_SHIFT) {
+		mod |= MOD_SHIFT
+	}
+	if isDown(w32.VK_LWIN) || isDown(w32.VK_RWIN) {
+		mod |= MOD_WIN
+	}
+	return mod
+}
+
+// findHookBinding returns the binding for a trigger, a mouse button or a
+// key caught by the keyboard hook, pressed with exactly the given
+// modifiers. Registration keeps combinations unique; should two match
+// anyway, the one with the lowest id wins rather than whichever the map
+// yields first.
+func findHookBinding(bindings map[int]HotKey, vk, mod int) (HotKey, bool) {
+	var found HotKey
+	ok := false
+	for _, hk := range bindings {
+		if hk.vk == vk && hk.mod&^MOD_NOREPEAT == mod && (!ok || hk.id < found.id) {
+			found, ok = hk, true
+		}
+	}
+	return found, ok
+}
+
+func isKeyDown(vk int) bool {
+	return w32.GetAsyncKeyState(vk)&0x8000 != 0
+}
+
+func mouseHookProc(nCode int, wParam w32.WPARAM, lParam w32.LPARAM) w32.LRESULT {
+	if nCode == 0 /* HC_ACTION */ {
+		info := *(**w32ex.MSLLHOOKSTRUCT)(unsafe.Pointer(&lParam))
+		if handleDragMouse(uint32(wParam), info) {
+			return 1
+		}
+		if vk, down, ok := mouseTrigger(uint32(wParam), info.MouseData); ok {
+			if !down {
+				if swallowedMouseUp[vk] {
+					delete(swallowedMouseUp, vk)
+					return 1
+				}
+			} else if hk, found := findHookBinding(mouseBindings, vk, modifierState(isKeyDown)); found {
+				if vk != VK_WHEEL_UP && vk != VK_WHEEL_DOWN {
+					swallowedMouseUp[vk] = true
+				}
+				maskModifierRelease(hk.mod)
+				// don't run the feature inside the hook, which has to return quickly
+				w32ex.PostThreadMessage(mouseHookThread, w32.WM_HOTKEY, uintptr(hk.id), 0)
+				return 1
+			}
+		}
+	}
+	return w32.CallNextHookEx(mouseHook, nCode, wParam, lParam)
+}
+
+// acquireMouseHook installs the hook for its first user, on the calling
+// thread, which must be the thread running msgLoop.
+func acquireMouseHook() bool {
+	if mouseHookUsers == 0 {
+		mouseHookThread = w32ex.GetCurrentThreadId()
+		mouseHook = w32.SetWindowsHookEx(w32.WH_MOUSE_LL, mouseHookProc, w32.GetModuleHandle(""), 0)
+		if mouseHook == 0 {
+			fmt.Printf("warn: failed to install mouse hook: %d\n", w32.GetLastError())
+			return false
+		}
+	}
+	mouseHookUsers++
+	return true
+}
+
+// releaseMouseHook removes the hook once its last user is gone.
+func releaseMouseHook() {
+	if mouseHookUsers == 0 {
+		// already uninstalled on exit
+		return
+	}
+	mouseHookUsers--
+	if mouseHookUsers == 0 {
+		w32.UnhookWindowsHookEx(mouseHook)
+		mouseHook = 0
+	}
+}
+
+// uninstallMouseHook removes the hook on exit, whoever still uses it.
+func uninstallMouseHook() {
+	if mouseHook != 0 {
+		w32.UnhookWindowsHookEx(mouseHook)
+		mouseHook = 0
+	}
+	mouseHookUsers = 0
+}
+
+// registerMouseBinding is RegisterHotKey for mouse triggers.
+func registerMouseBinding(h HotKey) bool {
+	if err := validateMouseBinding(h.mod, h.vk); err != nil {
+		fmt.Printf("warn: %s: %v\n", h.Describe(), err)
+		return false
+	}
+	if _, ok := mouseBindings[h.id]; ok {
+		return false
+	}
+	if dup, ok := findHookBinding(mouseBindings, h.vk, h.mod&^MOD_NOREPEAT); ok {
+		fmt.Printf("warn: %s is already bound to %s\n", h.Describe(), dup.bindFeature)
+		return false
+	}
+	if !acquireMouseHook() {
+		return false
+	}
+	mouseBindings[h.id] = h
+	return true
+}
+
+func unregisterMouseBinding(h HotKey) {
+	if _, ok := mouseBindings[h.id]; !ok {
+		return
+	}
+	delete(mouseBindings, h.id)
+	releaseMouseHook()
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestIsMouseKey(t *testing.T) {
+	for _, vk := range []int{w32.VK_LBUTTON, w32.VK_RBUTTON, w32.VK_MBUTTON, w32.VK_XBUTTON1, w32.VK_XBUTTON2, VK_WHEEL_UP, VK_WHEEL_DOWN} {
+		if !isMouseKey(vk) {
+			t.Errorf("isMouseKey(0x%x) = false, want true", vk)
+		}
+	}
+	for _, vk := range []int{0x03 /* VK_CANCEL */, 'A', w32.VK_ESCAPE} {
+		if isMouseKey(vk) {
+			t.Errorf("isMouseKey(0x%x) = true, want false", vk)
+		}
+	}
+}
+
+func TestValidateMouseBinding(t *testing.T) {
+	cases := []struct {
+		mod, vk int
+		wantErr bool
+	}{
+		{0, w32.VK_LBUTTON, true},
+		{MOD_NOREPEAT, w32.VK_RBUTTON, true},
+		{MOD_ALT | MOD_NOREPEAT, w32.VK_LBUTTON, false},
+		{0, w32.VK_MBUTTON, false},
+		{0, VK_WHEEL_UP, false},
+	}
+	for _, c := range cases {
+		if err := validateMouseBinding(c.mod, c.vk); (err != nil) != c.wantErr {
+			t.Errorf("validateMouseBinding(0x%x, 0x%x) = %v, wantErr %v", c.mod, c.vk, err, c.wantErr)
+		}
+	}
+}
+
+func TestMouseBindingProblem(t *testing.T) {
+	if got, want := mouseBindingProblem(HotKey{vk: w32.VK_LBUTTON}), "left and right mouse buttons need a modifier"; got != want {
+		t.Errorf("plain left click: %q, want %q", got, want)
+	}
+	if got, want := mouseBindingProblem(HotKey{mod: MOD_ALT, vk: w32.VK_MBUTTON}), "the mouse hook could not be installed"; got != want {
+		t.Errorf("valid binding: %q, want %q", got, want)
+	}
+}
+
+func TestMouseTrigger(t *testing.T) {
+	cases := []struct {
+		name      string
+		msg       uint32
+		mouseData uint32
+		vk        int
+		down, ok  bool
+	}{
+		{"left down", w32.WM_LBUTTONDOWN, 0, w32.VK_LBUTTON, true, true},
+		{"left up", w32.WM_LBUTTONUP, 0, w32.VK_LBUTTON, false, true},
+		{"right down", w32.WM_RBUTTONDOWN, 0, w32.VK_RBUTTON, true, true},
+		{"middle down", w32.WM_MBUTTONDOWN, 0, w32.VK_MBUTTON, true, true},
+		{"middle up", w32.WM_MBUTTONUP, 0, w32.VK_MBUTTON, false, true},
+		{"x1 down", w32.WM_XBUTTONDOWN, w32.XBUTTON1 << 16, w32.VK_XBUTTON1, true, true},
+		{"x2 up", w32.WM_XBUTTONUP, w32.XBUTTON2 << 16, w32.VK_XBUTTON2, false, true},
+		{"wheel up", w32.WM_MOUSEWHEEL, 120 << 16, VK_WHEEL_UP, true, true},
+		{"wheel down", w32.WM_MOUSEWHEEL, uint32(0xFF88) << 16, VK_WHEEL_DOWN, true, true}, // -120
+		{"wheel zero", w32.WM_MOUSEWHEEL, 0, 0, false, false},
+		{"move", w32.WM_MOUSEMOVE, 0, 0, false, false},
+	}
+	for _, c := range cases {
+		vk, down, ok := mouseTrigger(c.msg, c.mouseData)
+		if vk != c.vk || down != c.down || ok != c.ok {
+			t.Errorf("%s: mouseTrigger = (0x%x, %v, %v), want (0x%x, %v, %v)", c.name, vk, down, ok, c.vk, c.down, c.ok)
+		}
+	}
+}
+
+func TestModifierState(t *testing.T) {
+	held := map[int]bool{}
+	isDown := func(vk int) bool { return held[vk] }
+	if got := modifierState(isDown); got != 0 {
+		t.Errorf("modifierState() with nothing held = 0x%x, want 0", got)
+	}
+	held[w32.VK_CONTROL] = true
+	held[w32.VK_RWIN] = true
+	if got, want := modifierState(isDown), MOD_CONTROL|MOD_WIN; got != want {
+		t.Errorf("modifierState() = 0x%x, want 0x%x", got, want)
+	}
+	held = map[int]bool{w32.VK_MENU: true, w32.VK_SHIFT: true}
+	if got, want := modifierState(isDown), MOD_ALT|MOD_SHIFT; got != want {
+		t.Errorf("modifierState() = 0x%x, want 0x%x", got, want)
+	}
+}
+
+func TestFindHookBinding(t *testing.T) {
+	bindings := map[int]HotKey{
+		1: {id: 1, mod: MOD_ALT | MOD_NOREPEAT, vk: w32.VK_MBUTTON, bindFeature: "maximize"},
+		2: {id: 2, mod: MOD_WIN | MOD_NOREPEAT, vk: VK_WHEEL_UP, bindFeature: "makeLarger"},
+		3: {id: 3, mod: MOD_WIN | MOD_NOREPEAT, vk: VK_WHEEL_DOWN, bindFeature: "makeSmaller"},
+	}
+	if hk, ok := findHookBinding(bindings, w32.VK_MBUTTON, MOD_ALT); !ok || hk.id != 1 {
+		t.Errorf("Alt+middle = %v, %v, want binding 1", hk, ok)
+	}
+	if hk, ok := findHookBinding(bindings, VK_WHEEL_DOWN, MOD_WIN); !ok || hk.id != 3 {
+		t.Errorf("Win+wheel down = %v, %v, want binding 3", hk, ok)
+	}
+	if _, ok := findHookBinding(bindings, w32.VK_MBUTTON, 0); ok {
+		t.Error("middle click without Alt should not match")
+	}
+	if _, ok := findHookBinding(bindings, w32.VK_MBUTTON, MOD_ALT|MOD_SHIFT); ok {
+		t.Error("Alt+Shift+middle click should not match Alt+middle click")
+	}
+	for id := 10; id < 20; id++ {
+		bindings[id] = HotKey{id: id, mod: MOD_ALT, vk: w32.VK_MBUTTON, bindFeature: "maximize"}
+	}
+	for i := 0; i < 10; i++ {
+		if hk, _ := findHookBinding(bindings, w32.VK_MBUTTON, MOD_ALT); hk.id != 1 {
+			t.Fatalf("duplicate Alt+middle = binding %d, want the lowest id 1", hk.id)
+		}
+	}
+}
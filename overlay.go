@@ -0,0 +1,146 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+)
+
+// The overlay is a small topmost, non-activating popup used to show hints
+// (e.g. the leader-key bindings) on top of the target window's monitor.
+// It must be created and updated from the thread that runs msgLoop.
+
+const (
+	overlayClassName = "RectangleWinPlusOverlay"
+	overlayPadding   = 16
+	overlayAlpha     = 230
+	overlayBgColor   = 0x00302820 // COLORREF is 0x00BBGGRR
+	overlayFgColor   = 0x00FFFFFF
+	overlayTextFlags = w32.DT_LEFT | w32.DT_NOPREFIX | w32.DT_EXPANDTABS
+)
+
+var (
+	overlayHwnd w32.HWND
+	// draws the content of the overlay inside its padding
+	overlayPaint func(hdc w32.HDC, rc *w32.RECT)
+)
+
+func overlayWndProc(hwnd w32.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case w32.WM_PAINT:
+		var ps w32.PAINTSTRUCT
+		hdc := w32.BeginPaint(hwnd, &ps)
+		rc := w32.GetClientRect(hwnd)
+		brush := w32.CreateSolidBrush(overlayBgColor)
+		w32.FillRect(hdc, rc, brush)
+		w32.DeleteObject(w32.HGDIOBJ(brush))
+		w32.SelectObject(hdc, w32.GetStockObject(w32.DEFAULT_GUI_FONT))
+		w32.SetBkMode(hdc, w32.TRANSPARENT)
+		w32.SetTextColor(hdc, overlayFgColor)
+		rc.Left += overlayPadding
+		rc.Top += overlayPadding
+		rc.Right -= overlayPadding
+		rc.Bottom -= overlayPadding
+		if overlayPaint != nil {
+			overlayPaint(hdc, rc)
+		}
+		w32.EndPaint(hwnd, &ps)
+		return 0
+	case w32.WM_NCHITTEST:
+		return ^uintptr(0) // HTTRANSPARENT: let clicks fall through
+	}
+	return w32.DefWindowProc(hwnd, msg, wParam, lParam)
+}
+
+func createOverlay() error {
+	className, _ := syscall.UTF16PtrFromString(overlayClassName)
+	instance := w32.GetModuleHandle("")
+	wc := w32.WNDCLASSEX{
+		WndProc:   syscall.NewCallback(overlayWndProc),
+		Instance:  instance,
+		Cursor:    w32.LoadCursor(0, w32.MakeIntResource(w32.IDC_ARROW)),
+		ClassName: className,
+	}
+	wc.Size = uint32(unsafe.Sizeof(wc))
+	if w32.RegisterClassEx(&wc) == 0 {
+		return fmt.Errorf("failed to RegisterClassEx:%d", w32.GetLastError())
+	}
+	overlayHwnd = w32.CreateWindowEx(
+		w32.WS_EX_TOPMOST|w32.WS_EX_TOOLWINDOW|w32.WS_EX_NOACTIVATE|w32.WS_EX_LAYERED,
+		className, nil, w32.WS_POPUP,
+		0, 0, 0, 0, 0, 0, instance, nil)
+	if overlayHwnd == 0 {
+		return fmt.Errorf("failed to CreateWindowEx:%d", w32.GetLastError())
+	}
+	w32.SetLayeredWindowAttributes(overlayHwnd, 0, overlayAlpha, w32.LWA_ALPHA)
+	return nil
+}
+
+// showOverlay displays text centered on the work area of the monitor that
+// holds the foreground window, replacing whatever the overlay showed before.
+func showOverlay(text string) {
+	showOverlayWith(func(hdc w32.HDC, workArea w32.RECT) (int32, int32) {
+		textRect := w32.RECT{}
+		w32.DrawText(hdc, text, &textRect, overlayTextFlags|w32.DT_CALCRECT)
+		return textRect.Width(), textRect.Height()
+	}, func(hdc w32.HDC, rc *w32.RECT) {
+		w32.DrawText(hdc, text, rc, overlayTextFlags)
+	})
+}
+
+// showOverlayWith displays content drawn by paint, sized by measure
+// within the work area, the same way as showOverlay. Both are called with
+// the font of the overlay selected into hdc.
+func showOverlayWith(measure func(hdc w32.HDC, workArea w32.RECT) (width, height int32), paint func(hdc w32.HDC, rc *w32.RECT)) {
+	if overlayHwnd == 0 {
+		if err := createOverlay(); err != nil {
+			fmt.Printf("warn: overlay: %v\n", err)
+			return
+		}
+	}
+	var monInfo w32.MONITORINFO
+	mon := w32.MonitorFromWindow(w32.GetForegroundWindow(), w32.MONITOR_DEFAULTTONEAREST)
+	if !w32.GetMonitorInfo(mon, &monInfo) {
+		fmt.Printf("warn: overlay: failed to GetMonitorInfo:%d\n", w32.GetLastError())
+		return
+	}
+	overlayPaint = paint
+
+	// measure with the same font the paint handler uses
+	hdc := w32.GetDC(overlayHwnd)
+	w32.SelectObject(hdc, w32.GetStockObject(w32.DEFAULT_GUI_FONT))
+	work := monInfo.RcWork
+	work.Right -= 2 * overlayPadding
+	work.Bottom -= 2 * overlayPadding
+	width, height := measure(hdc, work)
+	w32.ReleaseDC(overlayHwnd, hdc)
+
+	width += 2 * overlayPadding
+	height += 2 * overlayPadding
+	pos := center(monInfo.RcWork, w32.RECT{Right: width, Bottom: height})
+	w32.SetWindowPos(overlayHwnd, w32.HWND_TOPMOST, int(pos.Left), int(pos.Top), int(width), int(height),
+		w32.SWP_NOACTIVATE|w32.SWP_SHOWWINDOW)
+	w32.InvalidateRect(overlayHwnd, nil, true)
+}
+
+func hideOverlay() {
+	if overlayHwnd != 0 {
+		w32.ShowWindow(overlayHwnd, w32.SW_HIDE)
+	}
+}
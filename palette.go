@@ -0,0 +1,53 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// The command palette lists every feature in the picker, so features
+// without a hotkey can still be found and run by name. The chosen feature
+// runs on the window that was focused before the palette opened.
+
+// paletteItems lists the features of registry that take no arguments by
+// display name, each with the hotkeys bound to it in hks.
+func paletteItems(registry map[string]featureRegistration, hks []HotKey) []pickerItem {
+	var items []pickerItem
+	for name, reg := range registry {
+		if name == "commandPalette" {
+			continue
+		}
+		if _, err := reg.New(nil); err != nil {
+			// needs arguments, only reachable through a hotkey
+			continue
+		}
+		var descs []string
+		for _, hk := range hks {
+			if hk.bindFeature == name && !hk.hasArgs {
+				descs = append(descs, hk.Describe())
+			}
+		}
+		items = append(items, pickerItem{
+			title:   reg.DisplayName,
+			detail:  strings.Join(descs, ", "),
+			search:  reg.DisplayName + " " + name,
+			feature: name,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].title < items[j].title })
+	return items
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaletteItems(t *testing.T) {
+	registry := map[string]featureRegistration{
+		"maximize":       simpleFeature("Maximize", func() {}),
+		"moveToCenter":   simpleFeature("Center", func() {}),
+		"minimize":       simpleFeature("Minimize", func() {}),
+		"moveToDisplay":  {"Move to Display", newMoveToDisplayFeature},
+		"commandPalette": simpleFeature("Command Palette", func() {}),
+	}
+	hks := []HotKey{
+		{mod: MOD_CONTROL | MOD_ALT, vk: 'C', bindFeature: "moveToCenter"},
+		{mod: MOD_WIN | MOD_ALT, vk: 'C', bindFeature: "moveToCenter"},
+		// bound with arguments, so not the feature as listed
+		{mod: MOD_CONTROL | MOD_ALT, vk: 'M', bindFeature: "maximize", hasArgs: true},
+	}
+	got := paletteItems(registry, hks)
+	want := []pickerItem{
+		{title: "Center", detail: "Ctrl + Alt + C key, Win + Alt + C key", search: "Center moveToCenter", feature: "moveToCenter"},
+		{title: "Maximize", search: "Maximize maximize", feature: "maximize"},
+		{title: "Minimize", search: "Minimize minimize", feature: "minimize"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paletteItems =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// features are found by their config name too
+	s := newPickerState(got)
+	for _, r := range "movetocen" {
+		s.typeRune(r)
+	}
+	if it, ok := s.current(); !ok || it.feature != "moveToCenter" {
+		t.Errorf("query %q selected %+v", string(s.query), it)
+	}
+}
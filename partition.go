@@ -0,0 +1,54 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// All layouts place their edges with cut, so that two layouts splitting
+// the same span meet exactly: the boundary at k/n of a span only depends
+// on the span and the fraction, never on which end it is measured from,
+// how the fraction is written (2/4 or 1/2) or what was rounded before.
+
+// cut returns the boundary k/n of the way along length pixels starting
+// at start, rounded down. n must be positive.
+func cut(start, length, k, n int32) int32 {
+	return start + int32(floorDiv(int64(length)*int64(k), int64(n)))
+}
+
+// splitSpan divides length pixels starting at start into n parts that add
+// up to exactly length and differ by at most one pixel. It returns the n+1
+// boundaries.
+func splitSpan(start, length int32, n int) []int32 {
+	bounds := make([]int32, n+1)
+	for i := 0; i <= n; i++ {
+		bounds[i] = cut(start, length, int32(i), int32(n))
+	}
+	return bounds
+}
+
+// centerSpan returns where a span of size length starts when centered in
+// space pixels starting at start. Like cut it rounds down, also when the
+// span is larger than the space.
+func centerSpan(start, space, length int32) int32 {
+	return cut(start, space-length, 1, 2)
+}
+
+// floorDiv divides rounding towards negative infinity, unlike Go's / which
+// rounds towards zero. b must be positive.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b < 0 {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestFloorDiv(t *testing.T) {
+	cases := []struct{ a, b, want int64 }{
+		{7, 2, 3}, {6, 2, 3}, {0, 5, 0}, {-1, 2, -1}, {-6, 2, -3}, {-7, 2, -4},
+	}
+	for _, c := range cases {
+		if got := floorDiv(c.a, c.b); got != c.want {
+			t.Errorf("floorDiv(%d, %d) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestSplitSpanExhaustive(t *testing.T) {
+	for _, start := range []int32{-1001, -7, 0, 13} {
+		for length := int32(0); length <= 257; length++ {
+			for n := 1; n <= 16; n++ {
+				bounds := splitSpan(start, length, n)
+				if bounds[0] != start || bounds[n] != start+length {
+					t.Fatalf("splitSpan(%d, %d, %d) = %v, doesn't span the length", start, length, n, bounds)
+				}
+				small := length / int32(n)
+				for i := 0; i < n; i++ {
+					if size := bounds[i+1] - bounds[i]; size != small && size != small+1 {
+						t.Fatalf("splitSpan(%d, %d, %d) = %v, part %d is %d px", start, length, n, bounds, i, size)
+					}
+				}
+				// the same fraction written differently cuts at the same place
+				for k := int32(0); k <= int32(n); k++ {
+					for m := int32(2); m <= 3; m++ {
+						if got := cut(start, length, k*m, int32(n)*m); got != bounds[k] {
+							t.Fatalf("cut(%d, %d, %d, %d) = %d, want %d", start, length, k*m, int32(n)*m, got, bounds[k])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// Every set of fraction layouts that together fill the display must do so
+// exactly, whatever its size: odd and even, landscape and portrait, and
+// left of or above the primary monitor.
+func TestFractionLayoutsTile(t *testing.T) {
+	layouts := map[string]resizeFunc{}
+	for _, l := range fractionLayouts {
+		layouts[l.name] = l.f
+	}
+	layouts["leftHalf"], layouts["rightHalf"] = leftHalf, rightHalf
+	layouts["topHalf"], layouts["bottomHalf"] = topHalf, bottomHalf
+	layouts["leftOneThirds"], layouts["rightTwoThirds"] = leftOneThirds, rightTwoThirds
+	layouts["leftTwoThirds"], layouts["rightOneThirds"] = leftTwoThirds, rightOneThirds
+	layouts["topOneThirds"], layouts["bottomTwoThirds"] = topOneThirds, bottomTwoThirds
+	layouts["topTwoThirds"], layouts["bottomOneThirds"] = topTwoThirds, bottomOneThirds
+
+	sets := [][]string{
+		{"leftHalf", "rightHalf"},
+		{"topHalf", "bottomHalf"},
+		{"leftOneThirds", "rightTwoThirds"},
+		{"leftTwoThirds", "rightOneThirds"},
+		{"topOneThirds", "bottomTwoThirds"},
+		{"topTwoThirds", "bottomOneThirds"},
+		{"firstThird", "centerThird", "lastThird"},
+		{"firstTwoThirds", "lastThird"},
+		{"firstThird", "lastTwoThirds"},
+		{"firstFourth", "secondFourth", "thirdFourth", "lastFourth"},
+		{"firstFourth", "centerHalf", "lastFourth"},
+		{"firstThreeFourths", "lastFourth"},
+		{"firstFourth", "lastThreeFourths"},
+		{"topLeftSixth", "topCenterSixth", "topRightSixth", "bottomLeftSixth", "bottomCenterSixth", "bottomRightSixth"},
+		{"topLeftEighth", "topCenterLeftEighth", "topCenterRightEighth", "topRightEighth",
+			"bottomLeftEighth", "bottomCenterLeftEighth", "bottomCenterRightEighth", "bottomRightEighth"},
+	}
+	for w := int32(1); w <= 61; w++ {
+		for _, h := range []int32{w - 1, w + 1, 2*w + 1} {
+			if h <= 0 {
+				continue
+			}
+			for _, disp := range []w32.RECT{rect(0, 0, w, h), rect(-w, -h, 0, 0), rect(h, 0, h+h, w)} {
+				for _, set := range sets {
+					var area int64
+					var rects []w32.RECT
+					for _, name := range set {
+						r := layouts[name](disp, disp)
+						if r.Left < disp.Left || r.Top < disp.Top || r.Right > disp.Right || r.Bottom > disp.Bottom {
+							t.Fatalf("%v: %s = %v is outside the display", disp, name, r)
+						}
+						for i, q := range rects {
+							if overlaps(r, q) {
+								t.Fatalf("%v: %s = %v overlaps %s = %v", disp, name, r, set[i], q)
+							}
+						}
+						rects = append(rects, r)
+						area += int64(r.Width()) * int64(r.Height())
+					}
+					if want := int64(disp.Width()) * int64(disp.Height()); area != want {
+						t.Fatalf("%v: %v cover %d px, want %d", disp, set, area, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCenterSpan(t *testing.T) {
+	cases := []struct{ start, space, length, want int32 }{
+		{0, 200, 100, 50},
+		{0, 201, 100, 50},
+		{0, 200, 101, 49},
+		{-100, 100, 50, -75},
+		{0, 100, 101, -1}, // larger than the space rounds down too
+		{0, 100, 103, -2},
+	}
+	for _, c := range cases {
+		if got := centerSpan(c.start, c.space, c.length); got != c.want {
+			t.Errorf("centerSpan(%d, %d, %d) = %d, want %d", c.start, c.space, c.length, got, c.want)
+		}
+	}
+}
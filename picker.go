@@ -0,0 +1,129 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/fuzzy"
+)
+
+// The picker is a searchable list, used by the window picker and the
+// command palette (palette.go). Typing filters the list with the fuzzy
+// package and Enter chooses the selected item.
+//
+// The window picker lists the zonable windows. Enter focuses the selected
+// window, and Ctrl, Shift or Alt+Enter also runs the feature PickerConfig
+// sets for that modifier on it.
+//
+// This file holds the list; picker_win.go the window.
+
+type pickerItem struct {
+	title string
+	// shown next to the title
+	detail string
+	// what typing is matched against
+	search string
+
+	// the window, in the window picker
+	hwnd w32.HWND
+	// the feature name, in the command palette
+	feature string
+}
+
+// windowItem is the window picker item of hwnd. display is 1-based, 0 if
+// unknown.
+func windowItem(hwnd w32.HWND, title, process string, display int) pickerItem {
+	detail := process
+	if display != 0 {
+		detail = fmt.Sprintf("%s · Display %d", process, display)
+	}
+	return pickerItem{title: title, detail: detail, search: title + " " + process, hwnd: hwnd}
+}
+
+// pickerState is the query typed into the picker and the items matching
+// it, one of them selected.
+type pickerState struct {
+	items    []pickerItem
+	query    []rune
+	matches  []pickerItem
+	selected int
+}
+
+func newPickerState(items []pickerItem) *pickerState {
+	s := &pickerState{items: items}
+	s.filter()
+	return s
+}
+
+func (s *pickerState) filter() {
+	texts := make([]string, len(s.items))
+	for i, it := range s.items {
+		texts[i] = it.search
+	}
+	s.matches = s.matches[:0]
+	for _, r := range fuzzy.Rank(string(s.query), texts) {
+		s.matches = append(s.matches, s.items[r.Index])
+	}
+	s.selected = 0
+}
+
+func (s *pickerState) typeRune(r rune) {
+	s.query = append(s.query, r)
+	s.filter()
+}
+
+func (s *pickerState) backspace() {
+	if len(s.query) > 0 {
+		s.query = s.query[:len(s.query)-1]
+		s.filter()
+	}
+}
+
+// move moves the selection by delta, stopping at the ends of the list.
+func (s *pickerState) move(delta int) {
+	if len(s.matches) == 0 {
+		return
+	}
+	s.selected += delta
+	if s.selected < 0 {
+		s.selected = 0
+	} else if s.selected >= len(s.matches) {
+		s.selected = len(s.matches) - 1
+	}
+}
+
+func (s *pickerState) current() (pickerItem, bool) {
+	if s.selected < len(s.matches) {
+		return s.matches[s.selected], true
+	}
+	return pickerItem{}, false
+}
+
+// pickerAction is the feature to run on the chosen window for the
+// modifiers held with Enter, "" to only focus it. Ctrl wins over Shift,
+// Shift over Alt.
+func pickerAction(pc PickerConfig, ctrl, shift, alt bool) string {
+	switch {
+	case ctrl:
+		return pc.CtrlEnter
+	case shift:
+		return pc.ShiftEnter
+	case alt:
+		return pc.AltEnter
+	}
+	return ""
+}
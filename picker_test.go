@@ -0,0 +1,89 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestPickerState(t *testing.T) {
+	s := newPickerState([]pickerItem{
+		windowItem(1, "README.md - Notepad", "notepad.exe", 1),
+		windowItem(2, "Inbox - Outlook", "OUTLOOK.EXE", 2),
+		windowItem(3, "Downloads", "explorer.exe", 1),
+	})
+	hwnds := func() []w32.HWND {
+		var out []w32.HWND
+		for _, it := range s.matches {
+			out = append(out, it.hwnd)
+		}
+		return out
+	}
+	if got := hwnds(); len(got) != 3 {
+		t.Fatalf("without a query all windows should be listed, got %v", got)
+	}
+
+	s.move(5)
+	if it, _ := s.current(); it.hwnd != 3 {
+		t.Errorf("move past the end selected %v, want the last window", it.hwnd)
+	}
+	// typing filters by process too, and resets the selection
+	for _, r := range "xpl" {
+		s.typeRune(r)
+	}
+	if it, ok := s.current(); !ok || it.hwnd != 3 || len(s.matches) != 1 {
+		t.Errorf("query %q matched %v", string(s.query), hwnds())
+	}
+	s.typeRune('z')
+	if _, ok := s.current(); ok {
+		t.Errorf("query %q should match nothing, got %v", string(s.query), hwnds())
+	}
+	s.move(1)
+	s.backspace()
+	s.backspace()
+	if string(s.query) != "xp" || len(s.matches) != 1 {
+		t.Errorf("after backspace query = %q, matches %v", string(s.query), hwnds())
+	}
+}
+
+func TestWindowItemDetail(t *testing.T) {
+	if got, want := windowItem(1, "main.go", "code.exe", 2).detail, "code.exe · Display 2"; got != want {
+		t.Errorf("detail = %q, want %q", got, want)
+	}
+	if got, want := windowItem(1, "main.go", "code.exe", 0).detail, "code.exe"; got != want {
+		t.Errorf("detail = %q, want %q", got, want)
+	}
+}
+
+func TestPickerAction(t *testing.T) {
+	pc := PickerConfig{CtrlEnter: "maximize", ShiftEnter: "moveToLeft"}
+	tests := []struct {
+		ctrl, shift, alt bool
+		want             string
+	}{
+		{false, false, false, ""},
+		{true, false, false, "maximize"},
+		{false, true, false, "moveToLeft"},
+		{true, true, false, "maximize"},
+		{false, false, true, ""},
+	}
+	for _, tt := range tests {
+		if got := pickerAction(pc, tt.ctrl, tt.shift, tt.alt); got != tt.want {
+			t.Errorf("pickerAction(ctrl=%v, shift=%v, alt=%v) = %q, want %q", tt.ctrl, tt.shift, tt.alt, got, tt.want)
+		}
+	}
+}
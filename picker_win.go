@@ -0,0 +1,277 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// The picker window, drawn like the overlay but taking the keyboard focus
+// so it can be typed into. Like the overlay it lives on the thread that
+// runs msgLoop. The window picker and the command palette share it.
+
+const (
+	pickerClassName   = "RectangleWinPlusPicker"
+	pickerWidth       = 640
+	pickerRows        = 12
+	pickerSelectColor = 0x00705030
+	pickerDimColor    = 0x00A0A0A0
+	pickerTextFlags   = w32.DT_SINGLELINE | w32.DT_VCENTER | w32.DT_NOPREFIX | w32.DT_END_ELLIPSIS
+)
+
+var (
+	pickerConfig PickerConfig
+	pickerHwnd   w32.HWND
+	picker       *pickerState
+	pickerRowH   int32
+	// called with the item chosen and the modifiers held with Enter
+	pickerChoose func(it pickerItem, ctrl, shift, alt bool)
+)
+
+// pickerWindows lists the windows to pick from in z-order, minimized ones
+// included.
+func pickerWindows() []pickerItem {
+	var items []pickerItem
+	w32.EnumWindows(func(hwnd w32.HWND) bool {
+		if !isZonableWindow(hwnd) || w32ex.IsCloaked(hwnd) {
+			return true
+		}
+		exe := w32ex.GetWindowModuleFileName(hwnd)
+		if exe == "" {
+			exe, _ = windowExePath(hwnd)
+		}
+		display := monitorIndex(w32.MonitorFromWindow(hwnd, w32.MONITOR_DEFAULTTONEAREST)) + 1
+		items = append(items, windowItem(hwnd, w32.GetWindowText(hwnd), filepath.Base(exe), display))
+		return true
+	})
+	return items
+}
+
+func pickerWndProc(hwnd w32.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case w32.WM_PAINT:
+		paintPicker(hwnd)
+		return 0
+	case w32.WM_CHAR:
+		switch r := rune(wParam); {
+		case r == 0x08: // Backspace
+			picker.backspace()
+		case r == 0x1B: // Escape
+			hidePicker()
+			return 0
+		case r >= 0x20 && r != 0x7F: // not Ctrl+Backspace
+			picker.typeRune(r)
+		default:
+			return 0
+		}
+		w32.InvalidateRect(hwnd, nil, true)
+		return 0
+	case w32.WM_KEYDOWN, w32.WM_SYSKEYDOWN:
+		switch wParam {
+		case w32.VK_UP:
+			picker.move(-1)
+		case w32.VK_DOWN:
+			picker.move(1)
+		case w32.VK_PRIOR:
+			picker.move(-pickerRows)
+		case w32.VK_NEXT:
+			picker.move(pickerRows)
+		case w32.VK_RETURN:
+			pressed := func(vk int) bool { return w32.GetKeyState(vk)&0x8000 != 0 }
+			it, ok := picker.current()
+			hidePicker()
+			if ok {
+				pickerChoose(it, pressed(w32.VK_CONTROL), pressed(w32.VK_SHIFT), pressed(w32.VK_MENU))
+			}
+			return 0
+		default:
+			return w32.DefWindowProc(hwnd, msg, wParam, lParam)
+		}
+		w32.InvalidateRect(hwnd, nil, true)
+		return 0
+	case w32.WM_SYSCHAR:
+		// no menu to open, so don't beep on Alt+Enter
+		return 0
+	case w32.WM_ACTIVATE:
+		if wParam&0xFFFF == w32.WA_INACTIVE {
+			hidePicker()
+		}
+		return 0
+	case w32.WM_CLOSE:
+		hidePicker()
+		return 0
+	}
+	return w32.DefWindowProc(hwnd, msg, wParam, lParam)
+}
+
+func paintPicker(hwnd w32.HWND) {
+	var ps w32.PAINTSTRUCT
+	hdc := w32.BeginPaint(hwnd, &ps)
+	defer w32.EndPaint(hwnd, &ps)
+	rc := w32.GetClientRect(hwnd)
+	brush := w32.CreateSolidBrush(overlayBgColor)
+	w32.FillRect(hdc, rc, brush)
+	w32.DeleteObject(w32.HGDIOBJ(brush))
+	w32.SelectObject(hdc, w32.GetStockObject(w32.DEFAULT_GUI_FONT))
+	w32.SetBkMode(hdc, w32.TRANSPARENT)
+
+	row := func(i int) *w32.RECT {
+		top := int32(overlayPadding) + int32(i)*pickerRowH
+		return &w32.RECT{Left: overlayPadding, Top: top, Right: rc.Right - overlayPadding, Bottom: top + pickerRowH}
+	}
+	w32.SetTextColor(hdc, overlayFgColor)
+	w32.DrawText(hdc, "> "+string(picker.query), row(0), pickerTextFlags)
+	if len(picker.matches) == 0 {
+		w32.SetTextColor(hdc, pickerDimColor)
+		w32.DrawText(hdc, "No matches", row(1), pickerTextFlags)
+		return
+	}
+	// scroll so the selection stays in view
+	first := 0
+	if picker.selected >= pickerRows {
+		first = picker.selected - pickerRows + 1
+	}
+	for i := first; i < len(picker.matches) && i < first+pickerRows; i++ {
+		it := picker.matches[i]
+		r := row(i - first + 1)
+		if i == picker.selected {
+			sel := w32.RECT{Left: 0, Top: r.Top, Right: rc.Right, Bottom: r.Bottom}
+			brush := w32.CreateSolidBrush(pickerSelectColor)
+			w32.FillRect(hdc, &sel, brush)
+			w32.DeleteObject(w32.HGDIOBJ(brush))
+		}
+		w32.SetTextColor(hdc, pickerDimColor)
+		w32.DrawText(hdc, it.detail, r, pickerTextFlags|w32.DT_RIGHT)
+		title := *r
+		title.Right -= r.Width() / 3
+		w32.SetTextColor(hdc, overlayFgColor)
+		w32.DrawText(hdc, it.title, &title, pickerTextFlags)
+	}
+}
+
+func createPicker() error {
+	className, _ := syscall.UTF16PtrFromString(pickerClassName)
+	instance := w32.GetModuleHandle("")
+	wc := w32.WNDCLASSEX{
+		WndProc:   syscall.NewCallback(pickerWndProc),
+		Instance:  instance,
+		Cursor:    w32.LoadCursor(0, w32.MakeIntResource(w32.IDC_ARROW)),
+		ClassName: className,
+	}
+	wc.Size = uint32(unsafe.Sizeof(wc))
+	if w32.RegisterClassEx(&wc) == 0 {
+		return fmt.Errorf("failed to RegisterClassEx:%d", w32.GetLastError())
+	}
+	pickerHwnd = w32.CreateWindowEx(
+		w32.WS_EX_TOPMOST|w32.WS_EX_TOOLWINDOW,
+		className, nil, w32.WS_POPUP|w32.WS_BORDER,
+		0, 0, 0, 0, 0, 0, instance, nil)
+	if pickerHwnd == 0 {
+		return fmt.Errorf("failed to CreateWindowEx:%d", w32.GetLastError())
+	}
+
+	hdc := w32.GetDC(pickerHwnd)
+	w32.SelectObject(hdc, w32.GetStockObject(w32.DEFAULT_GUI_FONT))
+	textRect := w32.RECT{}
+	w32.DrawText(hdc, "Ag", &textRect, w32.DT_SINGLELINE|w32.DT_CALCRECT)
+	w32.ReleaseDC(pickerHwnd, hdc)
+	pickerRowH = textRect.Height() + 8
+	return nil
+}
+
+// showPicker opens the picker listing items on the monitor of the
+// foreground window. choose is called with the item chosen.
+func showPicker(items []pickerItem, choose func(it pickerItem, ctrl, shift, alt bool)) {
+	if pickerHwnd == 0 {
+		if err := createPicker(); err != nil {
+			fmt.Printf("warn: picker: %v\n", err)
+			return
+		}
+	}
+	picker = newPickerState(items)
+	pickerChoose = choose
+
+	var monInfo w32.MONITORINFO
+	mon := w32.MonitorFromWindow(w32.GetForegroundWindow(), w32.MONITOR_DEFAULTTONEAREST)
+	if !w32.GetMonitorInfo(mon, &monInfo) {
+		fmt.Printf("warn: picker: failed to GetMonitorInfo:%d\n", w32.GetLastError())
+		return
+	}
+	height := (pickerRows+1)*pickerRowH + 2*overlayPadding
+	pos := center(monInfo.RcWork, w32.RECT{Right: pickerWidth, Bottom: height})
+	w32.SetWindowPos(pickerHwnd, w32.HWND_TOPMOST, int(pos.Left), int(pos.Top), pickerWidth, int(height),
+		w32.SWP_SHOWWINDOW)
+	w32.SetForegroundWindow(pickerHwnd)
+	w32.SetFocus(pickerHwnd)
+	w32.InvalidateRect(pickerHwnd, nil, true)
+}
+
+func hidePicker() {
+	if pickerHwnd != 0 && w32.IsWindowVisible(pickerHwnd) {
+		w32.ShowWindow(pickerHwnd, w32.SW_HIDE)
+	}
+}
+
+func showWindowPicker() {
+	showPicker(pickerWindows(), pickWindow)
+}
+
+// showCommandPalette lists the features, to run the one chosen on the
+// window focused before the palette.
+func showCommandPalette() {
+	target := getTargetWindow()
+	showPicker(paletteItems(featureRegistry, hks), func(it pickerItem, ctrl, shift, alt bool) {
+		if target != 0 && w32.IsWindow(target) {
+			w32.SetForegroundWindow(target)
+		}
+		callback, err := newFeature(it.feature, nil)
+		if err != nil {
+			fmt.Printf("warn: command palette: %v\n", err)
+			return
+		}
+		callback()
+	})
+}
+
+// pickWindow focuses the chosen window and runs the feature set for the
+// modifiers on it, if any.
+func pickWindow(it pickerItem, ctrl, shift, alt bool) {
+	if !w32.IsWindow(it.hwnd) {
+		return
+	}
+	if w32ex.IsIconic(it.hwnd) {
+		w32.ShowWindow(it.hwnd, w32.SW_RESTORE)
+	}
+	if !w32.SetForegroundWindow(it.hwnd) {
+		fmt.Printf("warn: window picker: failed to SetForegroundWindow:%d\n", w32.GetLastError())
+		return
+	}
+	feature := pickerAction(pickerConfig, ctrl, shift, alt)
+	if feature == "" {
+		return
+	}
+	callback, err := newFeature(feature, nil)
+	if err != nil {
+		fmt.Printf("warn: window picker: %v\n", err)
+		return
+	}
+	callback()
+}
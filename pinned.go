@@ -0,0 +1,230 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gonutz/w32/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Pinned windows, kept always on top by toggleAlwaysOnTop. Optionally a
+// colored border marks them, and the apps pinned are remembered in
+// pinnedAppsFile so their windows are pinned again when they open.
+//
+// The borders are windows of their own, created and moved on the thread
+// running msgLoop like the overlay, see pinned_win.go. Other threads ask
+// for an update by posting msgUpdatePinBorders to it.
+
+const (
+	pinBorderClassName = "RectangleWinPlusPinBorder"
+	pinnedAppsFile     = "pinned_apps.yaml"
+	// posted to the msgLoop thread to update the borders
+	msgUpdatePinBorders = w32.WM_APP + 1
+)
+
+var (
+	pinnedConfig PinnedConfig
+	pinThread    uint32
+	pinHooks     []uintptr
+	// the border of each pinned window, only touched on the msgLoop thread
+	pinBorders = map[w32.HWND]w32.HWND{}
+	pinnedApps appList
+)
+
+// appList is a set of executable paths, compared without case.
+type appList struct {
+	mu   sync.Mutex
+	apps []string
+}
+
+func (l *appList) has(path string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, a := range l.apps {
+		if strings.EqualFold(a, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// set adds or removes path, reporting whether the list changed.
+func (l *appList) set(path string, in bool) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, a := range l.apps {
+		if strings.EqualFold(a, path) {
+			if in {
+				return false
+			}
+			l.apps = append(l.apps[:i], l.apps[i+1:]...)
+			return true
+		}
+	}
+	if !in {
+		return false
+	}
+	l.apps = append(l.apps, path)
+	sort.Strings(l.apps)
+	return true
+}
+
+func (l *appList) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.apps...)
+}
+
+type pinnedAppsState struct {
+	Apps []string `yaml:"apps"`
+}
+
+func pinnedAppsPath() (string, error) {
+	configPath, err := getValidConfigPathOrCreate()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), pinnedAppsFile), nil
+}
+
+func loadPinnedApps() {
+	path, err := pinnedAppsPath()
+	if err != nil {
+		fmt.Printf("warn: pinned apps: %v\n", err)
+		return
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		fmt.Printf("warn: pinned apps: %v\n", err)
+		return
+	}
+	var state pinnedAppsState
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		fmt.Printf("warn: pinned apps: %s: %v\n", path, err)
+		return
+	}
+	for _, app := range state.Apps {
+		pinnedApps.set(app, true)
+	}
+}
+
+func savePinnedApps() {
+	path, err := pinnedAppsPath()
+	if err != nil {
+		fmt.Printf("warn: pinned apps: %v\n", err)
+		return
+	}
+	data, err := yaml.Marshal(pinnedAppsState{Apps: pinnedApps.list()})
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		fmt.Printf("warn: pinned apps: %v\n", err)
+	}
+}
+
+// rememberPin adds the app of hwnd to the remembered apps, or removes it.
+func rememberPin(hwnd w32.HWND, pinned bool) {
+	if !pinnedConfig.Remember {
+		return
+	}
+	path, err := windowExePath(hwnd)
+	if err != nil {
+		fmt.Printf("warn: pinned apps: %v\n", err)
+		return
+	}
+	if pinnedApps.set(path, pinned) {
+		savePinnedApps()
+	}
+}
+
+// togglePin is toggleAlwaysOnTop. Unlike pictureInPicture, which also
+// pins windows, it remembers the app.
+func togglePin() {
+	hwnd := getTargetWindow()
+	var pinned bool
+	if err := changeEffect(hwnd, func(e *windowEffect) {
+		togglePinned(e)
+		pinned = e.pinned
+	}); err != nil {
+		fmt.Printf("warn: toggleAlwaysOnTop: %v\n", err)
+		return
+	}
+	rememberPin(hwnd, pinned)
+}
+
+func unpin(hwnd w32.HWND) {
+	if err := changeEffect(hwnd, func(e *windowEffect) { e.pinned = false }); err != nil {
+		fmt.Printf("warn: unpin: %v\n", err)
+		return
+	}
+	rememberPin(hwnd, false)
+}
+
+func unpinAll() {
+	for _, w := range pinnedWindows() {
+		unpin(w.hwnd)
+	}
+}
+
+// pinnedWindows lists the windows pinned with toggleAlwaysOnTop or
+// pictureInPicture.
+func pinnedWindows() []effectWindow {
+	var list []effectWindow
+	for _, w := range effectWindows() {
+		if w.effect.pinned && !w.effect.origPinned() {
+			list = append(list, w)
+		}
+	}
+	return list
+}
+
+// repin pins hwnd if its app is remembered.
+func repin(hwnd w32.HWND) {
+	if !isZonableWindow(hwnd) || w32.GetWindowLong(hwnd, GWL_EXSTYLE)&w32.WS_EX_TOPMOST != 0 {
+		return
+	}
+	path, err := windowExePath(hwnd)
+	if err != nil || !pinnedApps.has(path) {
+		return
+	}
+	if err := changeEffect(hwnd, func(e *windowEffect) { e.pinned = true }); err != nil {
+		fmt.Printf("warn: pin %s: %v\n", path, err)
+	}
+}
+
+// pinBorderRect is the border window around the frame of a pinned window.
+func pinBorderRect(frame w32.RECT, width int32) w32.RECT {
+	return w32.RECT{Left: frame.Left - width, Top: frame.Top - width,
+		Right: frame.Right + width, Bottom: frame.Bottom + width}
+}
+
+// pinBorderKey is the color made transparent inside the border, which
+// must differ from the border's own color.
+func pinBorderKey(color w32.COLORREF) w32.COLORREF {
+	if color == 0x00FF00FF {
+		return 0x00FE00FF
+	}
+	return 0x00FF00FF
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestAppList(t *testing.T) {
+	var l appList
+	if !l.set(`C:\Windows\notepad.exe`, true) || !l.set(`C:\Apps\calc.exe`, true) {
+		t.Fatal("adding new apps should change the list")
+	}
+	if l.set(`c:\windows\NOTEPAD.EXE`, true) {
+		t.Error("apps should be compared without case")
+	}
+	if !l.has(`C:\WINDOWS\notepad.exe`) || l.has(`C:\Windows\mspaint.exe`) {
+		t.Error("has doesn't match the list")
+	}
+	if got, want := l.list(), []string{`C:\Apps\calc.exe`, `C:\Windows\notepad.exe`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list = %q, want %q", got, want)
+	}
+	if !l.set(`c:\apps\CALC.exe`, false) || l.set(`C:\Apps\calc.exe`, false) {
+		t.Error("remove should change the list once")
+	}
+	if got, want := l.list(), []string{`C:\Windows\notepad.exe`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list = %q, want %q", got, want)
+	}
+}
+
+func TestPinBorderRect(t *testing.T) {
+	frame := w32.RECT{Left: 100, Top: 50, Right: 900, Bottom: 650}
+	want := w32.RECT{Left: 97, Top: 47, Right: 903, Bottom: 653}
+	if got := pinBorderRect(frame, 3); got != want {
+		t.Errorf("pinBorderRect = %+v, want %+v", got, want)
+	}
+}
+
+func TestPinBorderKey(t *testing.T) {
+	for _, c := range []w32.COLORREF{0, 0x00D77800, 0x00FF00FF, 0x00FE00FF} {
+		if pinBorderKey(c) == c {
+			t.Errorf("pinBorderKey(0x%06X) is the border color", c)
+		}
+	}
+}
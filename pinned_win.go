@@ -0,0 +1,165 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// The window side of pinned.go: the event hooks and the border windows.
+
+// setupPinned installs the window event hooks for the borders and the
+// remembered apps, and pins the open windows of those apps. It must be
+// called on the thread running msgLoop.
+func setupPinned(pc PinnedConfig) {
+	pinnedConfig = pc
+	pinThread = w32ex.GetCurrentThreadId()
+	if !pc.Border && !pc.Remember {
+		return
+	}
+	ranges := [][2]uint32{{w32ex.EVENT_OBJECT_SHOW, w32ex.EVENT_OBJECT_SHOW}}
+	if pc.Border {
+		ranges = append(ranges,
+			[2]uint32{w32ex.EVENT_SYSTEM_MINIMIZESTART, w32ex.EVENT_SYSTEM_MINIMIZEEND},
+			[2]uint32{w32ex.EVENT_OBJECT_DESTROY, w32ex.EVENT_OBJECT_HIDE},
+			[2]uint32{w32ex.EVENT_OBJECT_LOCATIONCHANGE, w32ex.EVENT_OBJECT_LOCATIONCHANGE},
+			[2]uint32{w32ex.EVENT_OBJECT_CLOAKED, w32ex.EVENT_OBJECT_UNCLOAKED})
+		watchEffects(func() {
+			w32ex.PostThreadMessage(pinThread, msgUpdatePinBorders, 0, 0)
+		})
+	}
+	callback := syscall.NewCallback(pinnedWinEvent)
+	flags := uint32(w32ex.WINEVENT_OUTOFCONTEXT | w32ex.WINEVENT_SKIPOWNPROCESS)
+	for _, r := range ranges {
+		if hook := w32ex.SetWinEventHook(r[0], r[1], callback, flags); hook != 0 {
+			pinHooks = append(pinHooks, hook)
+		} else {
+			fmt.Printf("warn: failed to install window event hook: %d\n", w32.GetLastError())
+		}
+	}
+	if pc.Remember {
+		loadPinnedApps()
+		for _, hwnd := range zonableWindows() {
+			repin(hwnd)
+		}
+	}
+}
+
+func pinnedWinEvent(hook, event, hwnd, idObject, idChild, thread, time uintptr) uintptr {
+	if int32(idObject) != w32ex.OBJID_WINDOW || idChild != 0 {
+		return 0
+	}
+	w := w32.HWND(hwnd)
+	if event == w32ex.EVENT_OBJECT_SHOW && pinnedConfig.Remember {
+		repin(w)
+	}
+	if _, ok := pinBorders[w]; ok {
+		updatePinBorders()
+	}
+	return 0
+}
+
+func pinBorderWndProc(hwnd w32.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case w32.WM_PAINT:
+		var ps w32.PAINTSTRUCT
+		hdc := w32.BeginPaint(hwnd, &ps)
+		rc := w32.GetClientRect(hwnd)
+		brush := w32.CreateSolidBrush(uint32(pinnedConfig.Color))
+		w32.FillRect(hdc, rc, brush)
+		w32.DeleteObject(w32.HGDIOBJ(brush))
+		width := pinnedConfig.BorderWidth
+		inner := w32.RECT{Left: rc.Left + width, Top: rc.Top + width, Right: rc.Right - width, Bottom: rc.Bottom - width}
+		brush = w32.CreateSolidBrush(uint32(pinBorderKey(pinnedConfig.Color)))
+		w32.FillRect(hdc, &inner, brush)
+		w32.DeleteObject(w32.HGDIOBJ(brush))
+		w32.EndPaint(hwnd, &ps)
+		return 0
+	case w32.WM_NCHITTEST:
+		return ^uintptr(0) // HTTRANSPARENT: let clicks fall through
+	}
+	return w32.DefWindowProc(hwnd, msg, wParam, lParam)
+}
+
+var pinBorderClass *uint16
+
+func createPinBorder() (w32.HWND, error) {
+	instance := w32.GetModuleHandle("")
+	if pinBorderClass == nil {
+		className, _ := syscall.UTF16PtrFromString(pinBorderClassName)
+		wc := w32.WNDCLASSEX{
+			WndProc:   syscall.NewCallback(pinBorderWndProc),
+			Instance:  instance,
+			ClassName: className,
+		}
+		wc.Size = uint32(unsafe.Sizeof(wc))
+		if w32.RegisterClassEx(&wc) == 0 {
+			return 0, fmt.Errorf("failed to RegisterClassEx:%d", w32.GetLastError())
+		}
+		pinBorderClass = className
+	}
+	hwnd := w32.CreateWindowEx(
+		w32.WS_EX_TOPMOST|w32.WS_EX_TOOLWINDOW|w32.WS_EX_NOACTIVATE|w32.WS_EX_LAYERED|w32.WS_EX_TRANSPARENT,
+		pinBorderClass, nil, w32.WS_POPUP,
+		0, 0, 0, 0, 0, 0, instance, nil)
+	if hwnd == 0 {
+		return 0, fmt.Errorf("failed to CreateWindowEx:%d", w32.GetLastError())
+	}
+	w32.SetLayeredWindowAttributes(hwnd, pinBorderKey(pinnedConfig.Color), 0, w32.LWA_COLORKEY)
+	return hwnd, nil
+}
+
+// updatePinBorders creates, moves and removes the borders to match the
+// pinned windows. It runs on the msgLoop thread.
+func updatePinBorders() {
+	if !pinnedConfig.Border {
+		return
+	}
+	pinned := map[w32.HWND]bool{}
+	for _, w := range pinnedWindows() {
+		pinned[w.hwnd] = true
+	}
+	for hwnd, border := range pinBorders {
+		if !pinned[hwnd] {
+			w32.DestroyWindow(border)
+			delete(pinBorders, hwnd)
+		}
+	}
+	for hwnd := range pinned {
+		border, ok := pinBorders[hwnd]
+		if !ok {
+			var err error
+			if border, err = createPinBorder(); err != nil {
+				fmt.Printf("warn: pinned border: %v\n", err)
+				return
+			}
+			pinBorders[hwnd] = border
+		}
+		ok, frame := w32.DwmGetWindowAttributeEXTENDED_FRAME_BOUNDS(hwnd)
+		if !ok || !w32.IsWindowVisible(hwnd) || w32ex.IsIconic(hwnd) || w32ex.IsCloaked(hwnd) {
+			w32.ShowWindow(border, w32.SW_HIDE)
+			continue
+		}
+		r := pinBorderRect(frame, pinnedConfig.BorderWidth)
+		w32.SetWindowPos(border, w32.HWND_TOPMOST, int(r.Left), int(r.Top), int(r.Width()), int(r.Height()),
+			w32.SWP_NOACTIVATE|w32.SWP_SHOWWINDOW)
+		w32.InvalidateRect(border, nil, true)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Picture in picture: a small always on top window in a corner of the
+// work area. The window's placement and effects are saved when it enters
+// and put back when it leaves.
+
+type pipCorner string
+
+const (
+	pipTopLeft     pipCorner = "top_left"
+	pipTopRight    pipCorner = "top_right"
+	pipBottomLeft  pipCorner = "bottom_left"
+	pipBottomRight pipCorner = "bottom_right"
+)
+
+func (c pipCorner) valid() bool {
+	switch c {
+	case pipTopLeft, pipTopRight, pipBottomLeft, pipBottomRight:
+		return true
+	}
+	return false
+}
+
+// pipRect places a w by h window in corner of disp, margin pixels from its
+// edges. The window shrinks if it doesn't fit.
+func pipRect(disp w32.RECT, w, h int32, corner pipCorner, margin int32) w32.RECT {
+	w = max(1, min(w, disp.Width()-2*margin))
+	h = max(1, min(h, disp.Height()-2*margin))
+	r := w32.RECT{Left: disp.Left + margin, Top: disp.Top + margin}
+	if corner == pipTopRight || corner == pipBottomRight {
+		r.Left = disp.Right - margin - w
+	}
+	if corner == pipBottomLeft || corner == pipBottomRight {
+		r.Top = disp.Bottom - margin - h
+	}
+	r.Right, r.Bottom = r.Left+w, r.Top+h
+	return r
+}
+
+// pipState is how a window was before entering picture in picture.
+type pipState struct {
+	placement w32.WINDOWPLACEMENT
+	pinned    bool
+	opacity   int32
+}
+
+// pipConfig is set from the configuration in main.
+var pipConfig = PictureInPictureConfig{Width: DEFAULT_PIP_WIDTH, Height: DEFAULT_PIP_HEIGHT,
+	Corner: string(pipBottomRight), Margin: DEFAULT_PIP_MARGIN, Opacity: 100}
+
+var (
+	pipMu      sync.Mutex
+	pipWindows = map[w32.HWND]pipState{}
+)
+
+// togglePictureInPicture puts the target window into picture in picture,
+// or back the way it was.
+func togglePictureInPicture() {
+	hwnd := getTargetWindow()
+	if !isZonableWindow(hwnd) {
+		fmt.Printf("warn: pictureInPicture: not a zonable window: 0x%x\n", hwnd)
+		return
+	}
+	pipMu.Lock()
+	for w := range pipWindows {
+		if !w32.IsWindow(w) {
+			delete(pipWindows, w)
+		}
+	}
+	state, ok := pipWindows[hwnd]
+	delete(pipWindows, hwnd)
+	pipMu.Unlock()
+	if ok {
+		leavePictureInPicture(hwnd, state)
+	} else {
+		enterPictureInPicture(hwnd)
+	}
+}
+
+func enterPictureInPicture(hwnd w32.HWND) {
+	var state pipState
+	if !w32.GetWindowPlacement(hwnd, &state.placement) {
+		fmt.Printf("warn: pictureInPicture: failed to GetWindowPlacement:%d\n", w32.GetLastError())
+		return
+	}
+	cfg := pipConfig
+	err := changeEffect(hwnd, func(e *windowEffect) {
+		state.pinned, state.opacity = e.pinned, e.opacity
+		e.pinned = true
+		if cfg.Opacity < 100 {
+			e.opacity = cfg.Opacity
+		}
+	})
+	if err != nil {
+		fmt.Printf("warn: pictureInPicture: %v\n", err)
+	}
+	dpi := w32ex.GetDpiForWindow(hwnd)
+	w, h := sizePreset{width: cfg.Width, height: cfg.Height, logical: true}.size(w32.RECT{}, dpi)
+	lastResized = 0
+	if _, err := resize(hwnd, func(disp, _ w32.RECT) w32.RECT {
+		return pipRect(disp, w, h, pipCorner(cfg.Corner), cfg.Margin*dpi/baseDPI)
+	}); err != nil {
+		fmt.Printf("warn: pictureInPicture: %v\n", err)
+	}
+	pipMu.Lock()
+	pipWindows[hwnd] = state
+	pipMu.Unlock()
+}
+
+// leaveAllPictureInPicture puts every window in picture in picture back
+// the way it was, so none is left small in a corner on exit.
+func leaveAllPictureInPicture() {
+	pipMu.Lock()
+	windows := pipWindows
+	pipWindows = map[w32.HWND]pipState{}
+	pipMu.Unlock()
+	for hwnd, state := range windows {
+		if w32.IsWindow(hwnd) {
+			leavePictureInPicture(hwnd, state)
+		}
+	}
+}
+
+func leavePictureInPicture(hwnd w32.HWND, state pipState) {
+	placement := state.placement
+	if placement.ShowCmd != w32.SW_SHOWMAXIMIZED {
+		placement.ShowCmd = w32.SW_SHOWNORMAL
+	}
+	if !w32.SetWindowPlacement(hwnd, &placement) {
+		fmt.Printf("warn: pictureInPicture: failed to SetWindowPlacement:%d\n", w32.GetLastError())
+	}
+	if err := changeEffect(hwnd, func(e *windowEffect) {
+		e.pinned, e.opacity = state.pinned, state.opacity
+	}); err != nil {
+		fmt.Printf("warn: pictureInPicture: %v\n", err)
+	}
+}
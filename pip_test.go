@@ -0,0 +1,47 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestPipRect(t *testing.T) {
+	disp := w32.RECT{Left: 100, Top: 0, Right: 2020, Bottom: 1040}
+	tests := []struct {
+		corner pipCorner
+		want   w32.RECT
+	}{
+		{pipTopLeft, w32.RECT{Left: 116, Top: 16, Right: 596, Bottom: 286}},
+		{pipTopRight, w32.RECT{Left: 1524, Top: 16, Right: 2004, Bottom: 286}},
+		{pipBottomLeft, w32.RECT{Left: 116, Top: 754, Right: 596, Bottom: 1024}},
+		{pipBottomRight, w32.RECT{Left: 1524, Top: 754, Right: 2004, Bottom: 1024}},
+	}
+	for _, tt := range tests {
+		if got := pipRect(disp, 480, 270, tt.corner, 16); got != tt.want {
+			t.Errorf("pipRect(%s) = %+v, want %+v", tt.corner, got, tt.want)
+		}
+	}
+}
+
+func TestPipRectTooLarge(t *testing.T) {
+	disp := w32.RECT{Left: 0, Top: 0, Right: 400, Bottom: 300}
+	want := w32.RECT{Left: 10, Top: 10, Right: 390, Bottom: 290}
+	if got := pipRect(disp, 480, 320, pipBottomRight, 10); got != want {
+		t.Errorf("pipRect = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Size presets resize the window to an exact size or aspect ratio, see
+// SizePreset in conf.go. Each preset is registered as the feature
+// sizePresetPrefix + its name.
+
+const sizePresetPrefix = "size:"
+
+// the DPI at which logical pixels equal physical ones
+const baseDPI = 96
+
+type presetAnchor string
+
+const (
+	presetAnchorCenter  presetAnchor = "center"
+	presetAnchorTopLeft presetAnchor = "top_left"
+)
+
+// sizePreset is a parsed SizePreset. Exactly one of the size and the
+// ratio is set.
+type sizePreset struct {
+	name          string
+	width, height int32
+	// width and height are logical pixels, scaled by the window's DPI
+	logical        bool
+	ratioW, ratioH int32
+	anchor         presetAnchor
+}
+
+// size returns the size of the preset in physical pixels on the display,
+// before clamping.
+func (p sizePreset) size(disp w32.RECT, dpi int32) (int32, int32) {
+	if p.ratioW > 0 {
+		// as large as fits, filling the height where the display allows
+		h := disp.Height()
+		w := h * p.ratioW / p.ratioH
+		if w > disp.Width() {
+			w = disp.Width()
+			h = w * p.ratioH / p.ratioW
+		}
+		return w, h
+	}
+	if p.logical {
+		if dpi <= 0 {
+			dpi = baseDPI
+		}
+		return p.width * dpi / baseDPI, p.height * dpi / baseDPI
+	}
+	return p.width, p.height
+}
+
+// resizeFunc returns the resize function placing a window at the preset
+// size, clamped to the display. dpi is the DPI of the window.
+func (p sizePreset) resizeFunc(dpi int32) resizeFunc {
+	return func(disp, cur w32.RECT) w32.RECT {
+		w, h := p.size(disp, dpi)
+		w = min(w, disp.Width())
+		h = min(h, disp.Height())
+		if p.anchor == presetAnchorTopLeft {
+			// keep the top-left corner unless that pushes the window off
+			// the display
+			left := max(disp.Left, min(cur.Left, disp.Right-w))
+			top := max(disp.Top, min(cur.Top, disp.Bottom-h))
+			return w32.RECT{Left: left, Top: top, Right: left + w, Bottom: top + h}
+		}
+		left := centerSpan(disp.Left, disp.Width(), w)
+		top := centerSpan(disp.Top, disp.Height(), h)
+		return w32.RECT{Left: left, Top: top, Right: left + w, Bottom: top + h}
+	}
+}
+
+// sizePresetFeature resizes the target window to the preset.
+func sizePresetFeature(p sizePreset) func() {
+	return func() {
+		hwnd := getTargetWindow()
+		lastResized = 0
+		if _, err := resize(hwnd, p.resizeFunc(w32ex.GetDpiForWindow(hwnd))); err != nil {
+			fmt.Printf("warn: resize: %v\n", err)
+		}
+	}
+}
+
+// registerSizePresets adds a feature for each preset to featureRegistry
+// and returns their names.
+func registerSizePresets(presets []sizePreset) []string {
+	var names []string
+	for _, p := range presets {
+		name := sizePresetPrefix + p.name
+		if _, ok := featureRegistry[name]; ok {
+			fmt.Printf("warn: duplicate size preset %s\n", p.name)
+			continue
+		}
+		featureRegistry[name] = simpleFeature(sizePresetDisplayName(p.name), sizePresetFeature(p))
+		names = append(names, name)
+	}
+	return names
+}
+
+func sizePresetDisplayName(name string) string {
+	return "Size: " + name
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestSizePresetResize(t *testing.T) {
+	disp := rect(0, 40, 2560, 1440)
+	cur := rect(300, 200, 900, 700)
+	hd := sizePreset{name: "hd", width: 1280, height: 720, anchor: presetAnchorCenter}
+	cases := []struct {
+		name string
+		p    sizePreset
+		dpi  int32
+		cur  w32.RECT
+		want w32.RECT
+	}{
+		{"centered", hd, 96, cur, rect(640, 380, 1920, 1100)},
+		{"pixels ignore dpi", hd, 192, cur, rect(640, 380, 1920, 1100)},
+		{"logical", sizePreset{width: 640, height: 360, logical: true}, 192, cur, rect(640, 380, 1920, 1100)},
+		{"logical without dpi", sizePreset{width: 1280, height: 720, logical: true}, 0, cur, rect(640, 380, 1920, 1100)},
+		{"keep top left", sizePreset{width: 1280, height: 720, anchor: presetAnchorTopLeft}, 96, cur, rect(300, 200, 1580, 920)},
+		{"top left stays on the display", sizePreset{width: 1280, height: 720, anchor: presetAnchorTopLeft}, 96,
+			rect(2000, 1000, 2400, 1300), rect(1280, 720, 2560, 1440)},
+		{"clamped", sizePreset{width: 3840, height: 2160}, 96, cur, rect(0, 40, 2560, 1440)},
+		{"ratio fills the height", sizePreset{ratioW: 16, ratioH: 9}, 96, cur, rect(36, 40, 2524, 1440)},
+		{"ratio limited by the width", sizePreset{ratioW: 32, ratioH: 9}, 96, cur, rect(0, 380, 2560, 1100)},
+		{"portrait ratio", sizePreset{ratioW: 9, ratioH: 16, anchor: presetAnchorTopLeft}, 96, cur, rect(300, 40, 1087, 1440)},
+	}
+	for _, c := range cases {
+		if got := c.p.resizeFunc(c.dpi)(disp, c.cur); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRegisterSizePresets(t *testing.T) {
+	saved := featureRegistry
+	defer func() { featureRegistry = saved }()
+	featureRegistry = map[string]featureRegistration{}
+
+	names := registerSizePresets([]sizePreset{{name: "hd"}, {name: "wide"}, {name: "hd"}})
+	if !reflect.DeepEqual(names, []string{"size:hd", "size:wide"}) {
+		t.Errorf("registerSizePresets = %v", names)
+	}
+	if reg, ok := featureRegistry["size:wide"]; !ok || reg.DisplayName != "Size: wide" {
+		t.Errorf("size:wide registered as %+v, %v", reg, ok)
+	}
+	if _, err := newFeature("size:hd", nil); err != nil {
+		t.Errorf("newFeature(size:hd): %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"unsafe"
 
+	"github.com/gonutz/w32/v2"
 	"golang.org/x/sys/windows"
 )
 
@@ -120,3 +121,19 @@ func killAllRectangleWinPlusProcesses() error {
 	fmt.Println("All RectangleWinPlus.exe processes terminated")
 	return nil
 }
+
+// windowExePath returns the path of the executable that owns hwnd.
+func windowExePath(hwnd w32.HWND) (string, error) {
+	_, pid := w32.GetWindowThreadProcessId(hwnd)
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return "", fmt.Errorf("OpenProcess(%d): %v", pid, err)
+	}
+	defer windows.CloseHandle(h)
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	n := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(h, 0, &buf[0], &n); err != nil {
+		return "", fmt.Errorf("QueryFullProcessImageName(%d): %v", pid, err)
+	}
+	return windows.UTF16ToString(buf[:n]), nil
+}
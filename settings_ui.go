@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/getlantern/systray"
+	"gopkg.in/yaml.v3"
 )
 
 func openSettingsUI() {
@@ -50,3 +51,35 @@ func formatHotkey(kb KeyBinding) string {
 	parts = append(parts, kb.Key)
 	return strings.Join(parts, " + ")
 }
+
+func formatHotkeys(kbs []KeyBinding) string {
+	var parts []string
+	for _, kb := range kbs {
+		if kb.Key != "" {
+			parts = append(parts, formatHotkey(kb))
+		}
+	}
+	if len(parts) == 0 {
+		return "Not set"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatFeature describes the feature a binding triggers, including its
+// arguments, e.g. "Move to Display (2)".
+func formatFeature(kb KeyBinding) string {
+	name := featureDisplayNames[kb.BindFeature]
+	if name == "" {
+		name = kb.BindFeature
+	}
+	if kb.Args == nil {
+		return name
+	}
+	flow := *kb.Args
+	flow.Style |= yaml.FlowStyle
+	args, err := yaml.Marshal(&flow)
+	if err != nil {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.TrimSpace(string(args)))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gonutz/w32/v2"
 	"github.com/lxn/walk"
 	. "github.com/lxn/walk/declarative"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
 	"gopkg.in/yaml.v3"
 )
 
@@ -39,20 +40,24 @@ type SettingsWindowApp struct {
 	rows      []*HotkeyRow
 	recording *HotkeyRow
 	handlerID int
+	// extraBindings are bindings without a row: features that take
+	// arguments or are not listed in the UI. They are saved unchanged.
+	extraBindings []KeyBinding
 }
 
 type HotkeyRow struct {
 	Feature     string
 	DisplayName string
-	Binding     KeyBinding
+	Bindings    []KeyBinding
 	Button      *walk.PushButton
+	AddBtn      *walk.PushButton
 	ClearBtn    *walk.PushButton
 	Label       *walk.Label
 }
 
 func (r *HotkeyRow) UpdateText() {
 	if r.Button != nil {
-		r.Button.SetText(formatHotkey(r.Binding))
+		r.Button.SetText(formatHotkeys(r.Bindings))
 	}
 }
 
@@ -62,10 +67,14 @@ func runSettingsWindow() {
 	// Load current config
 	config := fetchConfiguration()
 
-	// Create a map of existing bindings for easy lookup
-	bindingMap := make(map[string]KeyBinding)
+	// Group existing bindings by feature; a feature may have several
+	bindingMap := make(map[string][]KeyBinding)
 	for _, kb := range config.Keybindings {
-		bindingMap[kb.BindFeature] = kb
+		if kb.Args != nil {
+			sw.extraBindings = append(sw.extraBindings, kb)
+			continue
+		}
+		bindingMap[kb.BindFeature] = append(bindingMap[kb.BindFeature], kb)
 	}
 
 	// Prepare ordered list of features
@@ -73,26 +82,56 @@ func runSettingsWindow() {
 		"moveToLeft", "moveToRight", "moveToTop", "moveToBottom",
 		"moveToTopLeft", "moveToTopRight", "moveToBottomLeft", "moveToBottomRight",
 		"moveToCenter", "maximize", "almostMaximize", "makeLarger", "makeSmaller", "makeFullHeight",
-		"nextDisplay", "prevDisplay", "toggleAlwaysOnTop",
+		"nextDisplay", "prevDisplay", "moveToNextDesktop", "moveToPrevDesktop", "toggleAlwaysOnTop",
+		"minimize", "minimizeOthers", "restoreAllMinimized", "hideToTray",
+		"toggleTransparency", "opacityUp", "opacityDown", "toggleClickThrough", "pictureInPicture",
+		"windowPicker", "commandPalette", "cheatSheet",
 		"pushToLeft", "pushToRight", "pushToTop", "pushToBottom",
+		"growLeft", "growRight", "growTop", "growBottom",
+		"shrinkLeft", "shrinkRight", "shrinkTop", "shrinkBottom",
+		"tileAll", "tileAllVertical", "tileAllHorizontal", "cascadeAll", "undo",
+		"swapWithNext", "swapLeft", "swapRight", "swapUp", "swapDown",
+		"focusLeft", "focusRight", "focusUp", "focusDown",
+		"leader", "adjust",
+		"toggleTiling", "cycleTilingLayout", "promoteToMaster", "swapTileNext", "swapTilePrev",
+		"growMaster", "shrinkMaster",
+	}
+	for _, l := range fractionLayouts {
+		orderedKeys = append(orderedKeys, l.name)
 	}
 
 	// Build rows
 	for _, key := range orderedKeys {
 		if displayName, ok := featureDisplayNames[key]; ok {
-			binding, exists := bindingMap[key]
-			if !exists {
-				binding = KeyBinding{BindFeature: key}
-			}
-
 			row := &HotkeyRow{
 				Feature:     key,
 				DisplayName: displayName,
-				Binding:     binding,
+				Bindings:    bindingMap[key],
 			}
+			delete(bindingMap, key)
 			sw.rows = append(sw.rows, row)
 		}
 	}
+	presetSeen := map[string]bool{}
+	for _, p := range parseSizePresets(config.SizePresets) {
+		key := sizePresetPrefix + p.name
+		if presetSeen[key] {
+			continue
+		}
+		presetSeen[key] = true
+		sw.rows = append(sw.rows, &HotkeyRow{
+			Feature:     key,
+			DisplayName: sizePresetDisplayName(p.name),
+			Bindings:    bindingMap[key],
+		})
+		delete(bindingMap, key)
+	}
+	// Keep bindings for features without a row
+	for _, kb := range config.Keybindings {
+		if _, ok := bindingMap[kb.BindFeature]; ok && kb.Args == nil {
+			sw.extraBindings = append(sw.extraBindings, kb)
+		}
+	}
 
 	if _, err := (MainWindow{
 		AssignTo: &sw.MainWindow,
@@ -134,6 +173,15 @@ func runSettingsWindow() {
 				Text: "Keyboard Shortcuts",
 				Font: Font{PointSize: 12, Bold: true},
 			},
+			Label{
+				Text:      "Click a shortcut to replace it, or + to add another one for the same action.",
+				TextColor: walk.RGB(100, 100, 100),
+			},
+			Label{
+				Visible:   len(sw.extraBindings) > 0,
+				Text:      fmt.Sprintf("%d binding(s) with arguments or for actions not listed here are kept as-is. Edit them in config.yaml.", len(sw.extraBindings)),
+				TextColor: walk.RGB(100, 100, 100),
+			},
 			ScrollView{
 				Layout: VBox{},
 				Children: []Widget{
@@ -221,10 +269,19 @@ func buildRowWidget(sw *SettingsWindowApp, r *HotkeyRow) Widget {
 			HSpacer{},
 			PushButton{
 				AssignTo: &r.Button,
-				Text:     formatHotkey(r.Binding),
+				Text:     formatHotkeys(r.Bindings),
 				MinSize:  Size{Width: 150, Height: 0},
 				OnClicked: func() {
-					startRecordingHotkey(sw, r)
+					startRecordingHotkey(sw, r, false)
+				},
+			},
+			PushButton{
+				AssignTo:    &r.AddBtn,
+				Text:        "+",
+				ToolTipText: "Add another shortcut",
+				MaxSize:     Size{Width: 30, Height: 0},
+				OnClicked: func() {
+					startRecordingHotkey(sw, r, true)
 				},
 			},
 			PushButton{
@@ -232,11 +289,7 @@ func buildRowWidget(sw *SettingsWindowApp, r *HotkeyRow) Widget {
 				Text:     "×",
 				MaxSize:  Size{Width: 30, Height: 0},
 				OnClicked: func() {
-					r.Binding.Key = ""
-					r.Binding.KeyCode = 0
-					r.Binding.Modifier = nil
-					r.Binding.ModifierCode = nil
-					r.Binding.CombinedMod = 0
+					r.Bindings = nil
 					r.UpdateText()
 				},
 			},
@@ -244,7 +297,9 @@ func buildRowWidget(sw *SettingsWindowApp, r *HotkeyRow) Widget {
 	}
 }
 
-func startRecordingHotkey(sw *SettingsWindowApp, row *HotkeyRow) {
+// startRecordingHotkey records a shortcut for row. With add set the new
+// shortcut is appended to the row's bindings, otherwise it replaces them.
+func startRecordingHotkey(sw *SettingsWindowApp, row *HotkeyRow, add bool) {
 	sw.recording = row
 
 	var dlg *walk.Dialog
@@ -307,21 +362,36 @@ func startRecordingHotkey(sw *SettingsWindowApp, row *HotkeyRow) {
 						Modifier:     modifiers,
 						ModifierCode: modCodes,
 						CombinedMod:  bitwiseOr(modCodes),
+						BindFeature:  sw.recording.Feature,
 					}
 
 					// Check for duplicate hotkey
-					if conflictRow := findHotkeyConflict(sw, sw.recording, tempBinding); conflictRow != nil {
-						errorMsg := fmt.Sprintf("This hotkey is already assigned to '%s'.\\n\\nPlease choose a different key combination.", conflictRow.DisplayName)
-						walk.MsgBox(dlg, "Duplicate Hotkey", errorMsg, walk.MsgBoxIconWarning)
+					if conflict := findHotkeyConflict(sw, sw.recording, !add, tempBinding); conflict != "" {
+						errorMsg := fmt.Sprintf("This hotkey is already assigned to '%s'.\n\nPlease choose a different key combination.", conflict)
+						walk.MsgBox(dlg, "Duplicate Hotkey", appendSuggestions(sw, add, tempBinding, errorMsg), walk.MsgBoxIconWarning)
 						return
 					}
+					// Check against Windows and other programs
+					if use, ok := reservedHotkeyUse(tempBinding.CombinedMod, keyCode); ok {
+						msg := fmt.Sprintf("%s is reserved by Windows for %s, so it may not work or may stop Windows from using it.", formatHotkey(tempBinding), use)
+						msg = appendSuggestions(sw, add, tempBinding, msg) + "\n\nUse it anyway?"
+						if walk.MsgBox(dlg, "Reserved Hotkey", msg, walk.MsgBoxYesNo|walk.MsgBoxIconWarning) != walk.DlgCmdYes {
+							return
+						}
+					} else if !hotkeyRegistrable(tempBinding.CombinedMod, keyCode) {
+						msg := fmt.Sprintf("%s is in use by another program, so it won't work while that program is running.", formatHotkey(tempBinding))
+						msg = appendSuggestions(sw, add, tempBinding, msg) + "\n\nUse it anyway?"
+						if walk.MsgBox(dlg, "Hotkey In Use", msg, walk.MsgBoxYesNo|walk.MsgBoxIconWarning) != walk.DlgCmdYes {
+							return
+						}
+					}
 
-					// Update binding
-					sw.recording.Binding.Key = keyName
-					sw.recording.Binding.KeyCode = keyCode
-					sw.recording.Binding.Modifier = modifiers
-					sw.recording.Binding.ModifierCode = modCodes
-					sw.recording.Binding.CombinedMod = bitwiseOr(modCodes)
+					// Update bindings
+					if add {
+						sw.recording.Bindings = append(sw.recording.Bindings, tempBinding)
+					} else {
+						sw.recording.Bindings = []KeyBinding{tempBinding}
+					}
 
 					// Update the row's button text
 					sw.recording.UpdateText()
@@ -343,32 +413,72 @@ func isModifierKey(key walk.Key) bool {
 	return key == walk.KeyControl || key == walk.KeyAlt || key == walk.KeyShift || key == walk.Key(w32.VK_LWIN) || key == walk.Key(w32.VK_RWIN)
 }
 
-// findHotkeyConflict checks if the given hotkey conflicts with any other row's hotkey
-// Returns the conflicting row if found, nil otherwise
-func findHotkeyConflict(sw *SettingsWindowApp, currentRow *HotkeyRow, newBinding KeyBinding) *HotkeyRow {
+// findHotkeyConflict checks if the given hotkey conflicts with any other binding.
+// The current row's own bindings are skipped when they are being replaced.
+// Returns the display name of the conflicting action if found, "" otherwise
+func findHotkeyConflict(sw *SettingsWindowApp, currentRow *HotkeyRow, replacing bool, newBinding KeyBinding) string {
 	// Empty hotkey cannot conflict
 	if newBinding.Key == "" {
-		return nil
+		return ""
 	}
 
 	for _, row := range sw.rows {
 		// Skip the current row being edited
-		if row == currentRow {
+		if row == currentRow && replacing {
 			continue
 		}
 
-		// Skip empty bindings
-		if row.Binding.Key == "" {
-			continue
+		for _, kb := range row.Bindings {
+			// Skip empty bindings
+			if kb.Key == "" {
+				continue
+			}
+
+			// Check if hotkeys match
+			if hotkeyMatches(kb, newBinding) {
+				return row.DisplayName
+			}
 		}
+	}
 
-		// Check if hotkeys match
-		if hotkeyMatches(row.Binding, newBinding) {
-			return row
+	for _, kb := range sw.extraBindings {
+		if hotkeyMatches(kb, newBinding) {
+			return formatFeature(kb)
 		}
 	}
 
-	return nil
+	return ""
+}
+
+// probeHotKeyID is the id of the hotkey registered by hotkeyRegistrable,
+// the largest one applications may use.
+const probeHotKeyID = 0xBFFF
+
+// hotkeyRegistrable reports whether mod+vk can be registered, i.e. no
+// other program holds it. The main app has exited while the settings
+// window is open, so our own hotkeys don't count.
+func hotkeyRegistrable(mod, vk int32) bool {
+	if isMouseKey(int(vk)) {
+		return true
+	}
+	if !w32ex.RegisterHotKey(0, probeHotKeyID, int(mod)|MOD_NOREPEAT, int(vk)) {
+		return false
+	}
+	w32ex.UnregisterHotKey(0, probeHotKeyID)
+	return true
+}
+
+// appendSuggestions adds free alternatives to the hotkey of kb, which
+// the row being recorded can take, to msg.
+func appendSuggestions(sw *SettingsWindowApp, add bool, kb KeyBinding, msg string) string {
+	free := func(mod, vk int32) bool {
+		candidate := KeyBinding{Key: kb.Key, KeyCode: vk, CombinedMod: mod}
+		return findHotkeyConflict(sw, sw.recording, !add, candidate) == "" && hotkeyRegistrable(mod, vk)
+	}
+	if s := describeSuggestions(suggestHotkeys(kb.CombinedMod, kb.KeyCode, free, 3)); s != "" {
+		msg += "\n\n" + s
+	}
+	return msg
 }
 
 // hotkeyMatches checks if two key bindings represent the same hotkey
@@ -387,24 +497,32 @@ func hotkeyMatches(kb1, kb2 KeyBinding) bool {
 }
 
 func saveSettings(sw *SettingsWindowApp) {
-	// Construct new configuration
-	newConfig := Configuration{}
+	// Only the keybindings are edited here
+	var keybindings []KeyBinding
 	for _, row := range sw.rows {
-		if row.Binding.Key != "" {
-			newConfig.Keybindings = append(newConfig.Keybindings, row.Binding)
+		for _, kb := range row.Bindings {
+			if kb.Key != "" {
+				keybindings = append(keybindings, kb)
+			}
 		}
 	}
+	keybindings = append(keybindings, sw.extraBindings...)
 
-	// Save to file
-	data, err := yaml.Marshal(newConfig)
+	configPath, err := getValidConfigPathOrCreate()
 	if err != nil {
-		walk.MsgBox(sw, "Error", "Failed to marshal config: "+err.Error(), walk.MsgBoxIconError)
+		walk.MsgBox(sw, "Error", "Failed to get config path: "+err.Error(), walk.MsgBoxIconError)
 		return
 	}
 
-	configPath, err := getValidConfigPathOrCreate()
+	// Save to file, keeping every other section as the user wrote it
+	old, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		walk.MsgBox(sw, "Error", "Failed to read config file: "+err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	data, err := replaceKeybindings(old, keybindings)
 	if err != nil {
-		walk.MsgBox(sw, "Error", "Failed to get config path: "+err.Error(), walk.MsgBoxIconError)
+		walk.MsgBox(sw, "Error", "Failed to marshal config: "+err.Error(), walk.MsgBoxIconError)
 		return
 	}
 
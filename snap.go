@@ -16,16 +16,35 @@ package main
 
 import "github.com/gonutz/w32/v2"
 
-// TODO find a way to round up divisions consistently, otherwise we end up with off by 1px
-
-func toLeft(d w32.RECT, mul, div int32) w32.RECT {
+// columnSpan is the full-height slice of d from from/div to to/div of its
+// width. Adjacent spans share their edge exactly, see cut.
+func columnSpan(d w32.RECT, from, to, div int32) w32.RECT {
 	return w32.RECT{
-		Left:   d.Left,
+		Left:   cut(d.Left, d.Width(), from, div),
 		Top:    d.Top,
-		Right:  d.Left + (d.Width()*mul)/div,
+		Right:  cut(d.Left, d.Width(), to, div),
 		Bottom: d.Top + d.Height()}
 }
 
+// rowSpan is the full-width slice of d from from/div to to/div of its
+// height.
+func rowSpan(d w32.RECT, from, to, div int32) w32.RECT {
+	return w32.RECT{
+		Left:   d.Left,
+		Top:    cut(d.Top, d.Height(), from, div),
+		Right:  d.Left + d.Width(),
+		Bottom: cut(d.Top, d.Height(), to, div)}
+}
+
+// gridCell is the cell at col, row of d split into cols by rows.
+func gridCell(d w32.RECT, col, row, cols, rows int32) w32.RECT {
+	return merge(columnSpan(d, col, col+1, cols), rowSpan(d, row, row+1, rows))
+}
+
+func toLeft(d w32.RECT, mul, div int32) w32.RECT {
+	return columnSpan(d, 0, mul, div)
+}
+
 func pushLeft(disp, cur w32.RECT) w32.RECT {
 	return w32.RECT{
 		Left:   disp.Left,
@@ -63,27 +82,15 @@ func pushBottom(disp, cur w32.RECT) w32.RECT {
 }
 
 func toRight(d w32.RECT, mul, div int32) w32.RECT {
-	return w32.RECT{
-		Left:   d.Left + d.Width() - d.Width()*mul/div,
-		Top:    d.Top,
-		Right:  d.Left + d.Width(),
-		Bottom: d.Top + d.Height()}
+	return columnSpan(d, div-mul, div, div)
 }
 
 func toTop(d w32.RECT, mul, div int32) w32.RECT {
-	return w32.RECT{
-		Left:   d.Left,
-		Top:    d.Top,
-		Right:  d.Left + d.Width(),
-		Bottom: d.Top + d.Height()*mul/div}
+	return rowSpan(d, 0, mul, div)
 }
 
 func toBottom(d w32.RECT, mul, div int32) w32.RECT {
-	return w32.RECT{
-		Left:   d.Left,
-		Top:    d.Top + d.Height() - d.Height()*mul/div,
-		Right:  d.Left + d.Width(),
-		Bottom: d.Top + d.Height()}
+	return rowSpan(d, div-mul, div, div)
 }
 
 func leftHalf(disp, _ w32.RECT) w32.RECT      { return toLeft(disp, 1, 2) }
@@ -106,6 +113,75 @@ func topLeftHalf(disp, _ w32.RECT) w32.RECT      { return merge(toLeft(disp, 1,
 func topLeftTwoThirds(disp, _ w32.RECT) w32.RECT { return merge(toLeft(disp, 2, 3), toTop(disp, 1, 2)) }
 func topLeftOneThirds(disp, _ w32.RECT) w32.RECT { return merge(toLeft(disp, 1, 3), toTop(disp, 1, 2)) }
 
+// alongSpan is the slice of d from from/div to to/div along its longer
+// side: columns on a landscape display, rows on a portrait one, as in
+// Rectangle.
+func alongSpan(d w32.RECT, from, to, div int32) w32.RECT {
+	if d.Height() > d.Width() {
+		return rowSpan(d, from, to, div)
+	}
+	return columnSpan(d, from, to, div)
+}
+
+// readingCell is cell i, in reading order, of d split into cols by rows,
+// or rows by cols on a portrait display.
+func readingCell(d w32.RECT, i, cols, rows int32) w32.RECT {
+	if d.Height() > d.Width() {
+		cols, rows = rows, cols
+	}
+	return gridCell(d, i%cols, i/cols, cols, rows)
+}
+
+func along(from, to, div int32) resizeFunc {
+	return func(disp, _ w32.RECT) w32.RECT { return alongSpan(disp, from, to, div) }
+}
+
+func sixth(i int32) resizeFunc {
+	return func(disp, _ w32.RECT) w32.RECT { return readingCell(disp, i, 3, 2) }
+}
+
+func eighth(i int32) resizeFunc {
+	return func(disp, _ w32.RECT) w32.RECT { return readingCell(disp, i, 4, 2) }
+}
+
+type namedLayout struct {
+	name string
+	f    resizeFunc
+}
+
+// fractionLayouts are the Rectangle layouts beyond the halves, thirds and
+// corners that moveToLeft and friends cycle through, in menu order.
+var fractionLayouts = []namedLayout{
+	{"centerHalf", along(1, 3, 4)},
+	{"firstThird", along(0, 1, 3)},
+	{"centerThird", along(1, 2, 3)},
+	{"lastThird", along(2, 3, 3)},
+	{"firstTwoThirds", along(0, 2, 3)},
+	{"centerTwoThirds", along(1, 5, 6)},
+	{"lastTwoThirds", along(1, 3, 3)},
+	{"firstFourth", along(0, 1, 4)},
+	{"secondFourth", along(1, 2, 4)},
+	{"thirdFourth", along(2, 3, 4)},
+	{"lastFourth", along(3, 4, 4)},
+	{"firstThreeFourths", along(0, 3, 4)},
+	{"centerThreeFourths", along(1, 7, 8)},
+	{"lastThreeFourths", along(1, 4, 4)},
+	{"topLeftSixth", sixth(0)},
+	{"topCenterSixth", sixth(1)},
+	{"topRightSixth", sixth(2)},
+	{"bottomLeftSixth", sixth(3)},
+	{"bottomCenterSixth", sixth(4)},
+	{"bottomRightSixth", sixth(5)},
+	{"topLeftEighth", eighth(0)},
+	{"topCenterLeftEighth", eighth(1)},
+	{"topCenterRightEighth", eighth(2)},
+	{"topRightEighth", eighth(3)},
+	{"bottomLeftEighth", eighth(4)},
+	{"bottomCenterLeftEighth", eighth(5)},
+	{"bottomCenterRightEighth", eighth(6)},
+	{"bottomRightEighth", eighth(7)},
+}
+
 func maxHeight(disp, cur w32.RECT) w32.RECT {
 	return w32.RECT{Left: cur.Left, Right: cur.Right, Top: disp.Top, Bottom: disp.Bottom}
 }
@@ -122,20 +198,171 @@ func max(a, b int32) int32 {
 	return b
 }
 
+// minWindowSize is the smallest width or height the features that shrink
+// a window step by step will leave it with.
+const minWindowSize = 100
+
+// resizeAnchor says which part of a window stays in place when
+// makeLarger and makeSmaller change its size.
+type resizeAnchor string
+
+const (
+	anchorCenter resizeAnchor = "center"
+	// the corner nearest to the matching corner of the display
+	anchorCorner resizeAnchor = "corner"
+	// the edges touching the display border, else the center; a window
+	// filling the whole display shrinks around its center
+	anchorEdge resizeAnchor = "edge"
+)
+
+// resizeOptions configures stepResize.
+type resizeOptions struct {
+	step   resizeStep
+	anchor resizeAnchor
+	// 0 only keeps the window from collapsing
+	minWidth, minHeight int32
+	// 0 allows up to the size of the display
+	maxWidth, maxHeight int32
+}
+
+var defaultResizeOptions = resizeOptions{step: resizeStep{Percent: 5}, anchor: anchorEdge}
+
+// stepResize moves the edges of the window by the step (sign = 1 to grow,
+// -1 to shrink), keeping the anchor in place, the size within the limits
+// and the window within the display.
+//
+// For a window within the size limits, unless a limit or the display
+// border was hit, makeLarger followed by makeSmaller returns the original
+// rect: both use the same step, and a window keeps its anchor across the
+// pair. For the corner anchor that holds while the window stays on the
+// same side of the display's middle, and for the edge anchor while it
+// doesn't reach another display border.
+func stepResize(opts resizeOptions, sign int32) resizeFunc {
+	return func(disp, cur w32.RECT) w32.RECT {
+		anchor := opts.anchor
+		if anchor == anchorEdge && cur.Left <= disp.Left && cur.Right >= disp.Right &&
+			cur.Top <= disp.Top && cur.Bottom >= disp.Bottom {
+			// with every edge on the border nothing could change
+			anchor = anchorCenter
+		}
+		left, right := resizeSpan(disp.Left, disp.Right, cur.Left, cur.Right,
+			sign*opts.step.amount(disp.Width()), anchor, opts.minWidth, opts.maxWidth)
+		top, bottom := resizeSpan(disp.Top, disp.Bottom, cur.Top, cur.Bottom,
+			sign*opts.step.amount(disp.Height()), anchor, opts.minHeight, opts.maxHeight)
+		return w32.RECT{Left: left, Right: right, Top: top, Bottom: bottom}
+	}
+}
+
+// resizeSpan moves both ends of the span lo-hi outwards by delta (inwards
+// if negative) along one axis of the display dlo-dhi, see stepResize.
+func resizeSpan(dlo, dhi, lo, hi, delta int32, anchor resizeAnchor, minSize, maxSize int32) (int32, int32) {
+	// which end stays put: -1 for lo, 1 for hi, 0 for neither
+	fixed := 0
+	switch anchor {
+	case anchorEdge:
+		atLo, atHi := lo <= dlo, hi >= dhi
+		if atLo && atHi {
+			// a span across the display keeps both ends on the borders
+			return max(dlo, lo), min(dhi, hi)
+		}
+		if atLo && !atHi {
+			fixed = -1
+		} else if atHi && !atLo {
+			fixed = 1
+		}
+	case anchorCorner:
+		if lo+hi < dlo+dhi {
+			fixed = -1
+		} else {
+			fixed = 1
+		}
+	}
+
+	cur := hi - lo
+	size := cur + 2*delta
+	if maxSize <= 0 || maxSize > dhi-dlo {
+		maxSize = dhi - dlo
+	}
+	if delta > 0 {
+		// growing never shrinks a window that is already above the limit
+		size = min(size, max(maxSize, cur))
+	} else {
+		// and shrinking never grows one below it
+		size = max(size, min(max(minSize, 1), cur))
+	}
+
+	switch fixed {
+	case -1:
+		hi = lo + size
+	case 1:
+		lo = hi - size
+	default:
+		lo -= (size - cur) / 2
+		hi = lo + size
+	}
+	return max(dlo, lo), min(dhi, hi)
+}
+
 // sign = 1 for positive. sign = -1 for negative.
 func resizeByPercent(disp, cur w32.RECT, sign int32) w32.RECT {
-	delta_x := (disp.Left - disp.Right) * sign / 20
-	delta_y := (disp.Top - disp.Bottom) * sign / 20
-
-	return w32.RECT{
-		Left:   max(disp.Left, cur.Left+delta_x),
-		Right:  min(disp.Right, cur.Right-delta_x),
-		Top:    max(disp.Top, cur.Top+delta_y),
-		Bottom: min(disp.Bottom, cur.Bottom-delta_y)}
+	return stepResize(defaultResizeOptions, sign)(disp, cur)
 }
 func makeLarger(disp, cur w32.RECT) w32.RECT  { return resizeByPercent(disp, cur, 1) }
 func makeSmaller(disp, cur w32.RECT) w32.RECT { return resizeByPercent(disp, cur, -1) }
 
+// resizeBy grows the window by dx, dy pixels around its center (negative
+// values shrink it), staying within the display. A resize that would leave
+// no width or height is ignored.
+func resizeBy(dx, dy int32) resizeFunc {
+	return func(disp, cur w32.RECT) w32.RECT {
+		if cur.Width()+dx <= 0 || cur.Height()+dy <= 0 {
+			return cur
+		}
+		left := cur.Left - dx/2
+		top := cur.Top - dy/2
+		return w32.RECT{
+			Left:   max(disp.Left, left),
+			Top:    max(disp.Top, top),
+			Right:  min(disp.Right, left+cur.Width()+dx),
+			Bottom: min(disp.Bottom, top+cur.Height()+dy)}
+	}
+}
+
+// growBy moves the right and bottom edges by dx, dy pixels, keeping the
+// top-left corner in place and the window within the display. Like
+// resizeBy, a resize that would leave no width or height is ignored.
+func growBy(dx, dy int32) resizeFunc {
+	return func(disp, cur w32.RECT) w32.RECT {
+		if cur.Width()+dx <= 0 || cur.Height()+dy <= 0 {
+			return cur
+		}
+		return w32.RECT{
+			Left:   cur.Left,
+			Top:    cur.Top,
+			Right:  min(disp.Right, cur.Right+dx),
+			Bottom: min(disp.Bottom, cur.Bottom+dy)}
+	}
+}
+
+// moveBy shifts the window by dx, dy pixels, stopping at the display edge
+// it is moving towards.
+func moveBy(dx, dy int32) resizeFunc {
+	return func(disp, cur w32.RECT) w32.RECT {
+		r := w32.RECT{Left: cur.Left + dx, Top: cur.Top + dy, Right: cur.Right + dx, Bottom: cur.Bottom + dy}
+		if dx < 0 && r.Left < disp.Left {
+			r = pushLeft(disp, r)
+		} else if dx > 0 && r.Right > disp.Right {
+			r = pushRight(disp, r)
+		}
+		if dy < 0 && r.Top < disp.Top {
+			r = pushTop(disp, r)
+		} else if dy > 0 && r.Bottom > disp.Bottom {
+			r = pushBottom(disp, r)
+		}
+		return r
+	}
+}
+
 func topRightHalf(disp, _ w32.RECT) w32.RECT { return merge(toRight(disp, 1, 2), toTop(disp, 1, 2)) }
 func topRightTwoThirds(disp, _ w32.RECT) w32.RECT {
 	return merge(toRight(disp, 2, 3), toTop(disp, 1, 2))
@@ -167,3 +394,120 @@ func bottomRightOneThirds(disp, _ w32.RECT) w32.RECT {
 func merge(x, y w32.RECT) w32.RECT {
 	return w32.RECT{Left: x.Left, Right: x.Right, Top: y.Top, Bottom: y.Bottom}
 }
+
+// gridLayout arranges n windows in a near-square grid: as many columns as
+// rows, or one more. A last row with fewer windows is shared evenly by them.
+func gridLayout(work w32.RECT, n int) []w32.RECT {
+	if n <= 0 {
+		return nil
+	}
+	cols := int32(1)
+	for cols*cols < int32(n) {
+		cols++
+	}
+	rows := (int32(n) + cols - 1) / cols
+	var rects []w32.RECT
+	for i := int32(0); i < int32(n); i++ {
+		row, col := i/cols, i%cols
+		inRow := cols
+		if row == rows-1 {
+			inRow = int32(n) - row*cols
+		}
+		rects = append(rects, gridCell(work, col, row, inRow, rows))
+	}
+	return rects
+}
+
+// columnsLayout puts n windows side by side.
+func columnsLayout(work w32.RECT, n int) []w32.RECT {
+	var rects []w32.RECT
+	for i := int32(0); i < int32(n); i++ {
+		rects = append(rects, columnSpan(work, i, i+1, int32(n)))
+	}
+	return rects
+}
+
+// rowsLayout stacks n windows on top of each other.
+func rowsLayout(work w32.RECT, n int) []w32.RECT {
+	var rects []w32.RECT
+	for i := int32(0); i < int32(n); i++ {
+		rects = append(rects, rowSpan(work, i, i+1, int32(n)))
+	}
+	return rects
+}
+
+// cascadeLayout overlaps n windows of two thirds of the work area, each
+// offset by step from the one before. When the next window would not fit,
+// the cascade starts again from the top-left corner.
+func cascadeLayout(work w32.RECT, n int, step int32) []w32.RECT {
+	w, h := cut(0, work.Width(), 2, 3), cut(0, work.Height(), 2, 3)
+	fit := int32(1)
+	if step > 0 {
+		fit = min((work.Width()-w)/step, (work.Height()-h)/step) + 1
+	}
+	var rects []w32.RECT
+	for i := int32(0); i < int32(n); i++ {
+		offset := (i % fit) * step
+		rects = append(rects, w32.RECT{
+			Left:   work.Left + offset,
+			Top:    work.Top + offset,
+			Right:  work.Left + offset + w,
+			Bottom: work.Top + offset + h})
+	}
+	return rects
+}
+
+// resizeStep is a step size given either in pixels or as a percentage of
+// the display.
+type resizeStep struct {
+	Pixels  int32
+	Percent int32
+}
+
+// amount returns the step in pixels for a display span of the given size.
+func (s resizeStep) amount(span int32) int32 {
+	if s.Percent != 0 {
+		return span * s.Percent / 100
+	}
+	return s.Pixels
+}
+
+// moveEdge moves one edge of the window by step: outwards when grow is
+// true, inwards otherwise. Growing stops at the display edge and shrinking
+// at minWindowSize; the other edges don't move.
+func moveEdge(edge direction, grow bool, step resizeStep) resizeFunc {
+	return func(disp, cur w32.RECT) w32.RECT {
+		r := cur
+		switch edge {
+		case dirLeft:
+			a := step.amount(disp.Width())
+			if grow {
+				r.Left = min(cur.Left, max(disp.Left, cur.Left-a))
+			} else {
+				r.Left = max(cur.Left, min(cur.Left+a, cur.Right-minWindowSize))
+			}
+		case dirRight:
+			a := step.amount(disp.Width())
+			if grow {
+				r.Right = max(cur.Right, min(disp.Right, cur.Right+a))
+			} else {
+				r.Right = min(cur.Right, max(cur.Right-a, cur.Left+minWindowSize))
+			}
+		case dirUp:
+			a := step.amount(disp.Height())
+			if grow {
+				r.Top = min(cur.Top, max(disp.Top, cur.Top-a))
+			} else {
+				r.Top = max(cur.Top, min(cur.Top+a, cur.Bottom-minWindowSize))
+			}
+		case dirDown:
+			a := step.amount(disp.Height())
+			if grow {
+				r.Bottom = max(cur.Bottom, min(disp.Bottom, cur.Bottom+a))
+			} else {
+				r.Bottom = min(cur.Bottom, max(cur.Bottom-a, cur.Top+minWindowSize))
+			}
+		}
+		return r
+	}
+}
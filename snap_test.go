@@ -1,8 +1,23 @@
+// Copyright 2022 Ahmet Alp Balkan
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package main
 
 import (
 	"reflect"
 	"testing"
+	"testing/quick"
 
 	"github.com/gonutz/w32/v2"
 )
@@ -112,3 +127,510 @@ func TestMakeLargerSmaller(t *testing.T) {
 		t.Errorf("makeSmaller = %+v, want %+v", got, rect(5, 5, 95, 95))
 	}
 }
+
+func TestResizeBy(t *testing.T) {
+	disp := rect(0, 0, 1000, 1000)
+	cur := rect(100, 100, 300, 300)
+	if got, want := resizeBy(100, 0)(disp, cur), rect(50, 100, 350, 300); !reflect.DeepEqual(got, want) {
+		t.Errorf("resizeBy(100, 0) = %+v, want %+v", got, want)
+	}
+	if got, want := resizeBy(-100, -50)(disp, cur), rect(150, 125, 250, 275); !reflect.DeepEqual(got, want) {
+		t.Errorf("resizeBy(-100, -50) = %+v, want %+v", got, want)
+	}
+	// clamped to the display
+	if got, want := resizeBy(400, 400)(disp, cur), rect(0, 0, 500, 500); !reflect.DeepEqual(got, want) {
+		t.Errorf("resizeBy(400, 400) = %+v, want %+v", got, want)
+	}
+	// never collapses the window
+	if got := resizeBy(-200, 0)(disp, cur); !reflect.DeepEqual(got, cur) {
+		t.Errorf("resizeBy(-200, 0) = %+v, want unchanged %+v", got, cur)
+	}
+}
+
+func TestGrowBy(t *testing.T) {
+	disp := rect(0, 0, 1000, 1000)
+	cur := rect(100, 100, 300, 300)
+	if got, want := growBy(20, 0)(disp, cur), rect(100, 100, 320, 300); !reflect.DeepEqual(got, want) {
+		t.Errorf("growBy(20, 0) = %+v, want %+v", got, want)
+	}
+	if got, want := growBy(0, -20)(disp, cur), rect(100, 100, 300, 280); !reflect.DeepEqual(got, want) {
+		t.Errorf("growBy(0, -20) = %+v, want %+v", got, want)
+	}
+	// clamped to the display
+	if got, want := growBy(900, 0)(disp, cur), rect(100, 100, 1000, 300); !reflect.DeepEqual(got, want) {
+		t.Errorf("growBy(900, 0) = %+v, want %+v", got, want)
+	}
+	// never collapses the window
+	if got := growBy(0, -200)(disp, cur); !reflect.DeepEqual(got, cur) {
+		t.Errorf("growBy(0, -200) = %+v, want unchanged %+v", got, cur)
+	}
+}
+
+func TestMoveBy(t *testing.T) {
+	disp := rect(0, 0, 1000, 1000)
+	cur := rect(100, 100, 300, 300)
+	if got, want := moveBy(50, -20)(disp, cur), rect(150, 80, 350, 280); !reflect.DeepEqual(got, want) {
+		t.Errorf("moveBy(50, -20) = %+v, want %+v", got, want)
+	}
+	// stops at the edge it moves towards
+	if got, want := moveBy(-500, 0)(disp, cur), rect(0, 100, 200, 300); !reflect.DeepEqual(got, want) {
+		t.Errorf("moveBy(-500, 0) = %+v, want %+v", got, want)
+	}
+	if got, want := moveBy(0, 900)(disp, cur), rect(100, 800, 300, 1000); !reflect.DeepEqual(got, want) {
+		t.Errorf("moveBy(0, 900) = %+v, want %+v", got, want)
+	}
+}
+
+func TestColumnAndRowSpan(t *testing.T) {
+	d := rect(10, 20, 1010, 920)
+	if got, want := columnSpan(d, 1, 2, 3), rect(343, 20, 676, 920); !reflect.DeepEqual(got, want) {
+		t.Errorf("columnSpan(1, 2, 3) = %+v, want %+v", got, want)
+	}
+	if got, want := rowSpan(d, 2, 3, 3), rect(10, 620, 1010, 920); !reflect.DeepEqual(got, want) {
+		t.Errorf("rowSpan(2, 3, 3) = %+v, want %+v", got, want)
+	}
+	// toLeft and toTop are the first span
+	if !reflect.DeepEqual(toLeft(d, 2, 3), columnSpan(d, 0, 2, 3)) || !reflect.DeepEqual(toTop(d, 1, 2), rowSpan(d, 0, 1, 2)) {
+		t.Error("toLeft/toTop differ from columnSpan/rowSpan")
+	}
+}
+
+func TestGridLayout(t *testing.T) {
+	work := rect(0, 0, 1200, 900)
+	got := gridLayout(work, 5)
+	want := []w32.RECT{
+		rect(0, 0, 400, 450), rect(400, 0, 800, 450), rect(800, 0, 1200, 450),
+		rect(0, 450, 600, 900), rect(600, 450, 1200, 900), // last row shared by two
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gridLayout(5) = %v, want %v", got, want)
+	}
+	if got := gridLayout(work, 4); !reflect.DeepEqual(got[3], rect(600, 450, 1200, 900)) || len(got) != 4 {
+		t.Errorf("gridLayout(4) = %v, want 2x2", got)
+	}
+	if got := gridLayout(work, 0); got != nil {
+		t.Errorf("gridLayout(0) = %v, want nil", got)
+	}
+}
+
+func TestArrangeLayoutsCoverWorkArea(t *testing.T) {
+	work := rect(-1280, 100, 0, 1123)
+	layouts := map[string]func(w32.RECT, int) []w32.RECT{
+		"grid": gridLayout, "columns": columnsLayout, "rows": rowsLayout,
+	}
+	for name, layout := range layouts {
+		for n := 1; n <= 10; n++ {
+			rects := layout(work, n)
+			if len(rects) != n {
+				t.Fatalf("%s(%d): got %d rects", name, n, len(rects))
+			}
+			var area int64
+			for i, r := range rects {
+				area += int64(r.Width()) * int64(r.Height())
+				for _, q := range rects[:i] {
+					if overlaps(r, q) {
+						t.Errorf("%s(%d): %v and %v overlap", name, n, q, r)
+					}
+				}
+			}
+			if want := int64(work.Width()) * int64(work.Height()); area != want {
+				t.Errorf("%s(%d): covers %d px, want %d", name, n, area, want)
+			}
+		}
+	}
+}
+
+func TestCascadeLayout(t *testing.T) {
+	work := rect(0, 0, 900, 600)
+	got := cascadeLayout(work, 3, 40)
+	want := []w32.RECT{rect(0, 0, 600, 400), rect(40, 40, 640, 440), rect(80, 80, 680, 480)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cascadeLayout(3) = %v, want %v", got, want)
+	}
+	// 200px of room vertically fits offsets 0..200, i.e. 6 windows, then wraps
+	got = cascadeLayout(work, 8, 40)
+	if !reflect.DeepEqual(got[6], got[0]) || !reflect.DeepEqual(got[7], got[1]) {
+		t.Errorf("cascade should wrap after 6 windows: %v", got)
+	}
+	for _, r := range got {
+		if r.Right > work.Right || r.Bottom > work.Bottom {
+			t.Errorf("cascade window %v outside the work area", r)
+		}
+	}
+}
+
+func TestMakeLargerSmallerAnchored(t *testing.T) {
+	disp := rect(0, 0, 1000, 1000)
+	cases := []struct {
+		name string
+		f    resizeFunc
+		cur  w32.RECT
+		want w32.RECT
+	}{
+		// left half: the left edge stays on the border, the right edge moves 2x
+		{"larger left half", makeLarger, rect(0, 0, 500, 1000), rect(0, 0, 600, 1000)},
+		{"smaller left half", makeSmaller, rect(0, 0, 500, 1000), rect(0, 0, 400, 1000)},
+		{"smaller right half", makeSmaller, rect(500, 0, 1000, 1000), rect(600, 0, 1000, 1000)},
+		{"larger bottom-right quarter", makeLarger, rect(500, 500, 1000, 1000), rect(400, 400, 1000, 1000)},
+		// away from the borders it grows around its center as before
+		{"larger floating", makeLarger, rect(300, 300, 600, 600), rect(250, 250, 650, 650)},
+		// filling the display it shrinks around its center
+		{"smaller full", makeSmaller, rect(0, 0, 1000, 1000), rect(50, 50, 950, 950)},
+	}
+	for _, c := range cases {
+		if got := c.f(disp, c.cur); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestResizeStepAmount(t *testing.T) {
+	if got := (resizeStep{Pixels: 40}).amount(1920); got != 40 {
+		t.Errorf("40px step = %d, want 40", got)
+	}
+	if got := (resizeStep{Percent: 5}).amount(1920); got != 96 {
+		t.Errorf("5%% step = %d, want 96", got)
+	}
+}
+
+func TestMoveEdge(t *testing.T) {
+	disp := rect(0, 0, 1000, 1000)
+	cur := rect(200, 200, 600, 600)
+	step := resizeStep{Pixels: 50}
+	cases := []struct {
+		name string
+		f    resizeFunc
+		cur  w32.RECT
+		want w32.RECT
+	}{
+		{"growRight", moveEdge(dirRight, true, step), cur, rect(200, 200, 650, 600)},
+		{"shrinkRight", moveEdge(dirRight, false, step), cur, rect(200, 200, 550, 600)},
+		{"growLeft", moveEdge(dirLeft, true, step), cur, rect(150, 200, 600, 600)},
+		{"shrinkLeft", moveEdge(dirLeft, false, step), cur, rect(250, 200, 600, 600)},
+		{"growTop", moveEdge(dirUp, true, step), cur, rect(200, 150, 600, 600)},
+		{"shrinkTop", moveEdge(dirUp, false, step), cur, rect(200, 250, 600, 600)},
+		{"growBottom", moveEdge(dirDown, true, step), cur, rect(200, 200, 600, 650)},
+		{"shrinkBottom", moveEdge(dirDown, false, step), cur, rect(200, 200, 600, 550)},
+		{"percent", moveEdge(dirRight, true, resizeStep{Percent: 10}), cur, rect(200, 200, 700, 600)},
+		{"grow stops at the display", moveEdge(dirLeft, true, step), rect(20, 200, 600, 600), rect(0, 200, 600, 600)},
+		{"shrink stops at the minimum", moveEdge(dirRight, false, step), rect(200, 200, 320, 600), rect(200, 200, 300, 600)},
+		{"already below the minimum", moveEdge(dirUp, false, step), rect(200, 200, 600, 260), rect(200, 200, 600, 260)},
+		{"outside the display", moveEdge(dirLeft, true, step), rect(-30, 200, 600, 600), rect(-30, 200, 600, 600)},
+	}
+	for _, c := range cases {
+		if got := c.f(disp, c.cur); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestStepResize(t *testing.T) {
+	disp := rect(0, 0, 1000, 1000)
+	px := func(n int32, anchor resizeAnchor) resizeOptions {
+		return resizeOptions{step: resizeStep{Pixels: n}, anchor: anchor}
+	}
+	cases := []struct {
+		name string
+		opts resizeOptions
+		sign int32
+		cur  w32.RECT
+		want w32.RECT
+	}{
+		{"center grows", px(50, anchorCenter), 1, rect(200, 200, 600, 600), rect(150, 150, 650, 650)},
+		{"center ignores edges", px(50, anchorCenter), 1, rect(0, 0, 500, 1000), rect(0, 0, 550, 1000)},
+		{"corner top left", px(50, anchorCorner), 1, rect(100, 100, 400, 400), rect(100, 100, 500, 500)},
+		{"corner bottom right", px(50, anchorCorner), -1, rect(600, 600, 900, 900), rect(700, 700, 900, 900)},
+		{"edge right half", px(50, anchorEdge), -1, rect(500, 0, 1000, 1000), rect(600, 0, 1000, 1000)},
+		{"percent", resizeOptions{step: resizeStep{Percent: 10}, anchor: anchorCenter}, 1, rect(200, 200, 600, 600), rect(100, 100, 700, 700)},
+		{"stops at the minimum", resizeOptions{step: resizeStep{Pixels: 50}, anchor: anchorCenter, minWidth: 250, minHeight: 100}, -1,
+			rect(200, 200, 500, 500), rect(225, 250, 475, 450)},
+		{"below the minimum stays", resizeOptions{step: resizeStep{Pixels: 50}, anchor: anchorCenter, minWidth: 400, minHeight: 400}, -1,
+			rect(200, 200, 500, 500), rect(200, 200, 500, 500)},
+		{"stops at the maximum", resizeOptions{step: resizeStep{Pixels: 50}, anchor: anchorCenter, maxWidth: 420, maxHeight: 600}, 1,
+			rect(200, 200, 600, 600), rect(190, 150, 610, 650)},
+		{"above the maximum stays", resizeOptions{step: resizeStep{Pixels: 50}, anchor: anchorCenter, maxWidth: 300, maxHeight: 300}, 1,
+			rect(200, 200, 600, 600), rect(200, 200, 600, 600)},
+		{"never collapses", px(50, anchorCenter), -1, rect(200, 200, 260, 260), rect(229, 229, 230, 230)},
+	}
+	for _, c := range cases {
+		if got := stepResize(c.opts, c.sign)(disp, c.cur); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+// anchorSide tells which end of the span lo-hi the anchor keeps in place,
+// mirroring resizeSpan: -1 for lo, 1 for hi, 2 for both and 0 for neither.
+func anchorSide(dlo, dhi, lo, hi int32, anchor resizeAnchor) int {
+	switch anchor {
+	case anchorEdge:
+		atLo, atHi := lo <= dlo, hi >= dhi
+		switch {
+		case atLo && atHi:
+			return 2
+		case atLo:
+			return -1
+		case atHi:
+			return 1
+		}
+	case anchorCorner:
+		if lo+hi < dlo+dhi {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// effectiveAnchor mirrors stepResize, which resizes a window filling the
+// display around its center.
+func effectiveAnchor(disp, cur w32.RECT, anchor resizeAnchor) resizeAnchor {
+	if anchor == anchorEdge && anchorSide(disp.Left, disp.Right, cur.Left, cur.Right, anchor) == 2 &&
+		anchorSide(disp.Top, disp.Bottom, cur.Top, cur.Bottom, anchor) == 2 {
+		return anchorCenter
+	}
+	return anchor
+}
+
+// roundTripCase builds a display, a window on it and resize options from
+// random numbers. span makes the window span the display horizontally
+// (bit 0) or vertically (bit 1), like a snapped one.
+func roundTripCase(a, b, c, d, e, f uint16, opt, span uint8) (w32.RECT, w32.RECT, resizeOptions) {
+	disp := rect(int32(a%500)-250, int32(b%500)-250, 0, 0)
+	disp.Right = disp.Left + 400 + int32(c%3000)
+	disp.Bottom = disp.Top + 400 + int32(d%2000)
+	w := 1 + int32(e)%(disp.Width()/2)
+	h := 1 + int32(f)%(disp.Height()/2)
+	left := disp.Left + int32(a/7)%(disp.Width()-w+1)
+	top := disp.Top + int32(b/7)%(disp.Height()-h+1)
+	cur := rect(left, top, left+w, top+h)
+	if span&1 != 0 {
+		cur.Left, cur.Right = disp.Left, disp.Right
+	}
+	if span&2 != 0 {
+		cur.Top, cur.Bottom = disp.Top, disp.Bottom
+	}
+
+	opts := resizeOptions{anchor: []resizeAnchor{anchorCenter, anchorCorner, anchorEdge}[opt%3]}
+	if opt&4 != 0 {
+		opts.step = resizeStep{Percent: 1 + int32(opt>>3)%20}
+	} else {
+		opts.step = resizeStep{Pixels: 1 + int32(opt>>3)*7}
+	}
+	if opt&8 != 0 {
+		opts.minWidth, opts.minHeight = 100, 100
+		opts.maxWidth, opts.maxHeight = 900, 700
+	}
+	return disp, cur, opts
+}
+
+// unclamped reports whether resizing cur into got moved every edge not
+// pinned to the display border by the full step and kept the window on
+// the same anchor, so no limit or display border got in the way, and
+// whether cur was within the size limits.
+func unclamped(disp, cur, got w32.RECT, opts resizeOptions, sign int32) bool {
+	within := func(size, lo, hi int32) bool {
+		return size >= max(lo, 1) && (hi <= 0 || size <= hi)
+	}
+	if !within(cur.Width(), opts.minWidth, opts.maxWidth) || !within(cur.Height(), opts.minHeight, opts.maxHeight) {
+		return false
+	}
+	curAnchor, gotAnchor := effectiveAnchor(disp, cur, opts.anchor), effectiveAnchor(disp, got, opts.anchor)
+	sideX := anchorSide(disp.Left, disp.Right, cur.Left, cur.Right, curAnchor)
+	sideY := anchorSide(disp.Top, disp.Bottom, cur.Top, cur.Bottom, curAnchor)
+	dx := sign * opts.step.amount(disp.Width())
+	dy := sign * opts.step.amount(disp.Height())
+	if sideX == 2 {
+		dx = 0
+	}
+	if sideY == 2 {
+		dy = 0
+	}
+	if got.Width() != cur.Width()+2*dx || got.Height() != cur.Height()+2*dy {
+		return false
+	}
+	return sideX == anchorSide(disp.Left, disp.Right, got.Left, got.Right, gotAnchor) &&
+		sideY == anchorSide(disp.Top, disp.Bottom, got.Top, got.Bottom, gotAnchor)
+}
+
+func TestStepResizeRoundTrip(t *testing.T) {
+	cfg := &quick.Config{MaxCount: 20000}
+	roundTrip := func(first int32) func(a, b, c, d, e, f uint16, opt, span uint8) bool {
+		checked := 0
+		t.Cleanup(func() {
+			if checked < 1000 {
+				t.Errorf("sign %d: only %d unclamped cases checked", first, checked)
+			}
+		})
+		return func(a, b, c, d, e, f uint16, opt, span uint8) bool {
+			disp, cur, opts := roundTripCase(a, b, c, d, e, f, opt, span)
+			once := stepResize(opts, first)(disp, cur)
+			if !unclamped(disp, cur, once, opts, first) {
+				return true
+			}
+			checked++
+			if got := stepResize(opts, -first)(disp, once); got != cur {
+				t.Logf("disp %+v opts %+v: %+v -> %+v -> %+v", disp, opts, cur, once, got)
+				return false
+			}
+			return true
+		}
+	}
+	if err := quick.Check(roundTrip(1), cfg); err != nil {
+		t.Errorf("makeLarger then makeSmaller: %v", err)
+	}
+	if err := quick.Check(roundTrip(-1), cfg); err != nil {
+		t.Errorf("makeSmaller then makeLarger: %v", err)
+	}
+}
+
+// TestStepResizeRoundTripCases checks, per anchor, that makeLarger and
+// makeSmaller undo each other while no limit or display border is hit,
+// and where the window ends up when one is.
+func TestStepResizeRoundTripCases(t *testing.T) {
+	disp := rect(0, 0, 1000, 1000)
+	px := func(anchor resizeAnchor) resizeOptions {
+		return resizeOptions{step: resizeStep{Pixels: 50}, anchor: anchor}
+	}
+	withMin := func(o resizeOptions, w, h int32) resizeOptions {
+		o.minWidth, o.minHeight = w, h
+		return o
+	}
+	withMax := func(o resizeOptions, w, h int32) resizeOptions {
+		o.maxWidth, o.maxHeight = w, h
+		return o
+	}
+	cases := []struct {
+		name string
+		opts resizeOptions
+		// sign of the first resize, the second one undoes it
+		first int32
+		cur   w32.RECT
+		want  w32.RECT
+	}{
+		{"center", px(anchorCenter), 1, rect(200, 200, 600, 600), rect(200, 200, 600, 600)},
+		{"center shrunk first", px(anchorCenter), -1, rect(200, 200, 600, 600), rect(200, 200, 600, 600)},
+		{"center at the display border", px(anchorCenter), 1, rect(20, 300, 420, 700), rect(50, 300, 420, 700)},
+		{"center at the maximum", withMax(px(anchorCenter), 420, 0), 1, rect(200, 200, 600, 600), rect(240, 200, 560, 600)},
+		{"center at the minimum", withMin(px(anchorCenter), 350, 0), -1, rect(200, 200, 600, 600), rect(175, 200, 625, 600)},
+
+		{"corner", px(anchorCorner), 1, rect(100, 100, 400, 400), rect(100, 100, 400, 400)},
+		{"corner shrunk first", px(anchorCorner), -1, rect(600, 600, 900, 900), rect(600, 600, 900, 900)},
+		{"corner across the middle", px(anchorCorner), 1, rect(400, 400, 580, 580), rect(500, 500, 680, 680)},
+		{"corner at the display border", px(anchorCorner), 1, rect(700, 100, 1000, 400), rect(700, 100, 1000, 400)},
+		{"corner at the minimum", withMin(px(anchorCorner), 250, 250), -1, rect(100, 100, 400, 400), rect(100, 100, 450, 450)},
+
+		{"edge", px(anchorEdge), 1, rect(0, 200, 500, 600), rect(0, 200, 500, 600)},
+		{"edge shrunk first", px(anchorEdge), -1, rect(500, 0, 1000, 1000), rect(500, 0, 1000, 1000)},
+		{"edge reaching the other border", px(anchorEdge), 1, rect(0, 200, 960, 600), rect(0, 200, 1000, 600)},
+		{"edge reaching its border", px(anchorEdge), 1, rect(30, 200, 430, 600), rect(0, 200, 380, 600)},
+		{"edge at the maximum", withMax(px(anchorEdge), 0, 420), 1, rect(0, 200, 500, 600), rect(0, 240, 500, 560)},
+	}
+	for _, c := range cases {
+		once := stepResize(c.opts, c.first)(disp, c.cur)
+		if got := stepResize(c.opts, -c.first)(disp, once); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: %+v -> %+v -> %+v, want %+v", c.name, c.cur, once, got, c.want)
+		}
+	}
+
+	// snapped windows with the defaults keep the axis they span pinned
+	wide := rect(0, 0, 1920, 1040)
+	for _, l := range fractionLayouts {
+		cur := l.f(wide, rect(100, 100, 500, 500))
+		once := makeLarger(wide, cur)
+		if got := makeSmaller(wide, once); got != cur {
+			t.Errorf("%s: %+v -> %+v -> %+v, want it back", l.name, cur, once, got)
+		}
+	}
+	for _, c := range []struct {
+		name string
+		cur  w32.RECT
+	}{
+		{"leftHalf", rect(0, 0, 960, 1040)},
+		{"rightTwoThirds", rect(640, 0, 1920, 1040)},
+	} {
+		once := makeLarger(wide, c.cur)
+		if got := makeSmaller(wide, once); got != c.cur {
+			t.Errorf("%s: %+v -> %+v -> %+v, want it back", c.name, c.cur, once, got)
+		}
+	}
+}
+
+func TestFractionLayouts(t *testing.T) {
+	wide := rect(0, 0, 1200, 600)
+	tall := rect(0, 0, 600, 1200)
+	want := map[string][2]w32.RECT{
+		"centerHalf":              {rect(300, 0, 900, 600), rect(0, 300, 600, 900)},
+		"firstThird":              {rect(0, 0, 400, 600), rect(0, 0, 600, 400)},
+		"centerThird":             {rect(400, 0, 800, 600), rect(0, 400, 600, 800)},
+		"lastThird":               {rect(800, 0, 1200, 600), rect(0, 800, 600, 1200)},
+		"firstTwoThirds":          {rect(0, 0, 800, 600), rect(0, 0, 600, 800)},
+		"centerTwoThirds":         {rect(200, 0, 1000, 600), rect(0, 200, 600, 1000)},
+		"lastTwoThirds":           {rect(400, 0, 1200, 600), rect(0, 400, 600, 1200)},
+		"firstFourth":             {rect(0, 0, 300, 600), rect(0, 0, 600, 300)},
+		"secondFourth":            {rect(300, 0, 600, 600), rect(0, 300, 600, 600)},
+		"thirdFourth":             {rect(600, 0, 900, 600), rect(0, 600, 600, 900)},
+		"lastFourth":              {rect(900, 0, 1200, 600), rect(0, 900, 600, 1200)},
+		"firstThreeFourths":       {rect(0, 0, 900, 600), rect(0, 0, 600, 900)},
+		"centerThreeFourths":      {rect(150, 0, 1050, 600), rect(0, 150, 600, 1050)},
+		"lastThreeFourths":        {rect(300, 0, 1200, 600), rect(0, 300, 600, 1200)},
+		"topLeftSixth":            {rect(0, 0, 400, 300), rect(0, 0, 300, 400)},
+		"topCenterSixth":          {rect(400, 0, 800, 300), rect(300, 0, 600, 400)},
+		"topRightSixth":           {rect(800, 0, 1200, 300), rect(0, 400, 300, 800)},
+		"bottomLeftSixth":         {rect(0, 300, 400, 600), rect(300, 400, 600, 800)},
+		"bottomCenterSixth":       {rect(400, 300, 800, 600), rect(0, 800, 300, 1200)},
+		"bottomRightSixth":        {rect(800, 300, 1200, 600), rect(300, 800, 600, 1200)},
+		"topLeftEighth":           {rect(0, 0, 300, 300), rect(0, 0, 300, 300)},
+		"topCenterLeftEighth":     {rect(300, 0, 600, 300), rect(300, 0, 600, 300)},
+		"topCenterRightEighth":    {rect(600, 0, 900, 300), rect(0, 300, 300, 600)},
+		"topRightEighth":          {rect(900, 0, 1200, 300), rect(300, 300, 600, 600)},
+		"bottomLeftEighth":        {rect(0, 300, 300, 600), rect(0, 600, 300, 900)},
+		"bottomCenterLeftEighth":  {rect(300, 300, 600, 600), rect(300, 600, 600, 900)},
+		"bottomCenterRightEighth": {rect(600, 300, 900, 600), rect(0, 900, 300, 1200)},
+		"bottomRightEighth":       {rect(900, 300, 1200, 600), rect(300, 900, 600, 1200)},
+	}
+	if len(fractionLayouts) != len(want) {
+		t.Errorf("got %d fraction layouts, want %d", len(fractionLayouts), len(want))
+	}
+	for _, l := range fractionLayouts {
+		if featureDisplayNames[l.name] == "" {
+			t.Errorf("%s has no display name", l.name)
+		}
+		w, ok := want[l.name]
+		if !ok {
+			t.Errorf("unexpected layout %s", l.name)
+			continue
+		}
+		if got := l.f(wide, rect(10, 10, 20, 20)); got != w[0] {
+			t.Errorf("%s on a landscape display = %+v, want %+v", l.name, got, w[0])
+		}
+		if got := l.f(tall, rect(10, 10, 20, 20)); got != w[1] {
+			t.Errorf("%s on a portrait display = %+v, want %+v", l.name, got, w[1])
+		}
+	}
+}
+
+// Complementary fractions must meet exactly, with no gap or overlap,
+// whatever the size and offset of the display.
+func TestFractionEdgesMeet(t *testing.T) {
+	for w := int32(1); w <= 50; w++ {
+		d := rect(-7, 3, -7+w, 3+w+1)
+		for div := int32(1); div <= 8; div++ {
+			for mul := int32(0); mul <= div; mul++ {
+				l, r := toLeft(d, mul, div), toRight(d, div-mul, div)
+				if l.Left != d.Left || l.Right != r.Left || r.Right != d.Right {
+					t.Fatalf("width %d, %d/%d: left %+v and right %+v don't tile %+v", w, mul, div, l, r, d)
+				}
+				tp, b := toTop(d, mul, div), toBottom(d, div-mul, div)
+				if tp.Top != d.Top || tp.Bottom != b.Top || b.Bottom != d.Bottom {
+					t.Fatalf("height %d, %d/%d: top %+v and bottom %+v don't tile %+v", w+1, mul, div, tp, b, d)
+				}
+			}
+		}
+	}
+	// leftOneThirds + rightTwoThirds used to leave a 1px gap
+	d := rect(0, 0, 1001, 500)
+	if l, r := leftOneThirds(d, d), rightTwoThirds(d, d); l.Right != r.Left {
+		t.Errorf("leftOneThirds %+v and rightTwoThirds %+v leave a gap", l, r)
+	}
+}
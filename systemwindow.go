@@ -32,6 +32,19 @@ func isZonableWindow(hwnd w32.HWND) bool {
 	return isStandardWindow(hwnd) && hasNoVisibleOwner(hwnd)
 }
 
+// zonableWindows lists the zonable windows the user can currently see, in
+// z-order from the topmost down. Minimized and cloaked windows are left out.
+func zonableWindows() []w32.HWND {
+	var windows []w32.HWND
+	w32.EnumWindows(func(hwnd w32.HWND) bool {
+		if isZonableWindow(hwnd) && !w32ex.IsIconic(hwnd) && !w32ex.IsCloaked(hwnd) {
+			windows = append(windows, hwnd)
+		}
+		return true
+	})
+	return windows
+}
+
 func hasNoVisibleOwner(hwnd w32.HWND) bool {
 	owner := w32.GetWindow(hwnd, w32.GW_OWNER)
 	if owner == 0 {
@@ -78,7 +91,8 @@ func isStandardWindow(hwnd w32.HWND) bool {
 
 	className, ok := w32.GetClassName(hwnd)
 	if !ok {
-		panic("GetClassName failed")
+		// the window was destroyed while we looked at it
+		return false
 	}
 	return !isSystemClassName(className)
 }
@@ -0,0 +1,239 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+
+	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
+)
+
+// Automatic tiling. Tiling is turned on per monitor; the zonable windows
+// on a tiled monitor are arranged with tileLayout and arranged again when
+// windows open, close, are minimized or restored, or are dropped after a
+// move. Window events only schedule a re-tile through a thread timer, so a
+// burst of them (an app opening several windows) is handled once.
+
+// retileDelayMs is how long to wait for more window events before tiling.
+const retileDelayMs = 100
+
+type tiledMonitor struct {
+	layout        tilingLayout
+	masterPercent int32
+	order         []w32.HWND
+}
+
+var (
+	tilingConfig TilingConfig
+	// tray callbacks run on their own goroutines, hotkeys and window
+	// events on the msgLoop thread
+	tilingMu      sync.Mutex
+	tiledMonitors = make(map[w32.HMONITOR]*tiledMonitor)
+	tilingHooks   []uintptr
+	retileTimer   uintptr
+)
+
+// setupTiling installs the window event hooks and tiles the displays
+// listed in the configuration. It must be called on the thread running
+// msgLoop, which is where the hooks deliver their events.
+func setupTiling(tc TilingConfig) {
+	tilingConfig = tc
+	callback := syscall.NewCallback(tilingWinEvent)
+	flags := uint32(w32ex.WINEVENT_OUTOFCONTEXT | w32ex.WINEVENT_SKIPOWNPROCESS)
+	for _, r := range [][2]uint32{
+		{w32ex.EVENT_SYSTEM_MOVESIZEEND, w32ex.EVENT_SYSTEM_MINIMIZEEND},
+		{w32ex.EVENT_OBJECT_DESTROY, w32ex.EVENT_OBJECT_HIDE},
+	} {
+		if hook := w32ex.SetWinEventHook(r[0], r[1], callback, flags); hook != 0 {
+			tilingHooks = append(tilingHooks, hook)
+		} else {
+			fmt.Printf("warn: failed to install window event hook: %d\n", w32.GetLastError())
+		}
+	}
+	for _, n := range tc.Displays {
+		mon, ok := monitorAt(n - 1)
+		if !ok {
+			fmt.Printf("warn: tiling: no display #%d\n", n)
+			continue
+		}
+		setTiling(mon, true)
+	}
+}
+
+func tilingWinEvent(hook, event, hwnd, idObject, idChild, thread, time uintptr) uintptr {
+	switch event {
+	case w32ex.EVENT_SYSTEM_MOVESIZEEND, w32ex.EVENT_SYSTEM_MINIMIZESTART, w32ex.EVENT_SYSTEM_MINIMIZEEND,
+		w32ex.EVENT_OBJECT_DESTROY, w32ex.EVENT_OBJECT_SHOW, w32ex.EVENT_OBJECT_HIDE:
+	default:
+		return 0
+	}
+	if int32(idObject) != w32ex.OBJID_WINDOW || idChild != 0 {
+		return 0
+	}
+	tilingMu.Lock()
+	tiling := len(tiledMonitors) > 0
+	tilingMu.Unlock()
+	if tiling {
+		scheduleRetile()
+	}
+	return 0
+}
+
+func scheduleRetile() {
+	if retileTimer != 0 {
+		w32ex.KillTimer(0, retileTimer)
+	}
+	retileTimer = w32.SetTimer(0, 0, retileDelayMs, 0)
+}
+
+// retileTimerFired is called by msgLoop when the retile timer fires.
+func retileTimerFired() {
+	w32ex.KillTimer(0, retileTimer)
+	retileTimer = 0
+	retileAll()
+}
+
+func retileAll() {
+	tilingMu.Lock()
+	defer tilingMu.Unlock()
+	windows := zonableWindows()
+	for mon := range tiledMonitors {
+		retileLocked(mon, windows)
+	}
+}
+
+// tilableWindows returns the windows from windows that belong on mon.
+// Maximized windows are left out so maximizing a window temporarily takes
+// it out of the layout.
+func tilableWindows(mon w32.HMONITOR, windows []w32.HWND) []w32.HWND {
+	var result []w32.HWND
+	for _, hwnd := range windows {
+		if w32ex.IsZoomed(hwnd) || w32.MonitorFromWindow(hwnd, w32.MONITOR_DEFAULTTONEAREST) != mon {
+			continue
+		}
+		result = append(result, hwnd)
+	}
+	return result
+}
+
+func retileLocked(mon w32.HMONITOR, windows []w32.HWND) {
+	t := tiledMonitors[mon]
+	t.order = reconcileOrder(t.order, tilableWindows(mon, windows))
+	var monInfo w32.MONITORINFO
+	if !w32.GetMonitorInfo(mon, &monInfo) {
+		fmt.Printf("warn: tiling: failed to GetMonitorInfo:%d\n", w32.GetLastError())
+		return
+	}
+	// placed without activating them, as a retile often runs in the
+	// background and the focus should stay where it is
+	rects := tileLayout(t.layout, monInfo.RcWork, len(t.order), t.masterPercent)
+	for i, hwnd := range t.order {
+		if err := placeWindow(hwnd, rects[i]); err != nil {
+			fmt.Printf("warn: tiling: %v\n", err)
+		}
+	}
+}
+
+func setTiling(mon w32.HMONITOR, on bool) {
+	tilingMu.Lock()
+	defer tilingMu.Unlock()
+	if !on {
+		delete(tiledMonitors, mon)
+		return
+	}
+	tiledMonitors[mon] = &tiledMonitor{
+		layout:        tilingLayout(tilingConfig.Layout),
+		masterPercent: tilingConfig.MasterPercent,
+	}
+	retileLocked(mon, zonableWindows())
+}
+
+// targetMonitor is the monitor of the target window, or the one under the
+// cursor if there is no target window.
+func targetMonitor() w32.HMONITOR {
+	if hwnd := getTargetWindow(); hwnd != 0 {
+		return w32.MonitorFromWindow(hwnd, w32.MONITOR_DEFAULTTONEAREST)
+	}
+	x, y, _ := w32.GetCursorPos()
+	return w32.MonitorFromPoint(x, y, w32.MONITOR_DEFAULTTONEAREST)
+}
+
+func toggleTiling() {
+	mon := targetMonitor()
+	tilingMu.Lock()
+	_, on := tiledMonitors[mon]
+	tilingMu.Unlock()
+	fmt.Printf("tiling on monitor 0x%x: %v\n", mon, !on)
+	setTiling(mon, !on)
+}
+
+// updateTiling changes the state of the tiled monitor of the target
+// window and arranges it again. It does nothing on untiled monitors.
+func updateTiling(f func(t *tiledMonitor, hwnd w32.HWND)) {
+	hwnd := getTargetWindow()
+	mon := targetMonitor()
+	tilingMu.Lock()
+	defer tilingMu.Unlock()
+	t, ok := tiledMonitors[mon]
+	if !ok {
+		fmt.Println("warn: tiling is off on this monitor")
+		return
+	}
+	windows := zonableWindows()
+	t.order = reconcileOrder(t.order, tilableWindows(mon, windows))
+	f(t, hwnd)
+	retileLocked(mon, windows)
+}
+
+func cycleTilingLayout() {
+	updateTiling(func(t *tiledMonitor, _ w32.HWND) { t.layout = t.layout.next() })
+}
+
+func promoteToMaster() {
+	updateTiling(func(t *tiledMonitor, hwnd w32.HWND) { t.order = promoteInOrder(t.order, hwnd) })
+}
+
+func swapTile(delta int) {
+	updateTiling(func(t *tiledMonitor, hwnd w32.HWND) { t.order = swapInOrder(t.order, hwnd, delta) })
+}
+
+func changeMasterPercent(delta int32) {
+	updateTiling(func(t *tiledMonitor, _ w32.HWND) {
+		t.masterPercent = clampMasterPercent(t.masterPercent + delta)
+	})
+}
+
+// swapTiledWindows swaps two windows in the tiling order of mon and
+// arranges it again. It reports false if mon is not tiled.
+func swapTiledWindows(mon w32.HMONITOR, a, b w32.HWND) bool {
+	tilingMu.Lock()
+	defer tilingMu.Unlock()
+	t, ok := tiledMonitors[mon]
+	if !ok {
+		return false
+	}
+	windows := zonableWindows()
+	t.order = reconcileOrder(t.order, tilableWindows(mon, windows))
+	i, j := indexOfWindow(t.order, a), indexOfWindow(t.order, b)
+	if i < 0 || j < 0 {
+		return false
+	}
+	t.order[i], t.order[j] = t.order[j], t.order[i]
+	retileLocked(mon, windows)
+	return true
+}
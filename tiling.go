@@ -0,0 +1,176 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/gonutz/w32/v2"
+)
+
+// Tiling layouts. These are pure functions from a window count and a work
+// area to one rect per window, in tiling order; tiler.go applies them.
+
+type tilingLayout string
+
+const (
+	// the first window takes the left part of the work area, the others
+	// share the rest as a vertical stack
+	layoutMasterStack tilingLayout = "master_stack"
+	// each window takes part of the remaining area, split along its longer
+	// side, and leaves the rest to the windows after it
+	layoutBSP tilingLayout = "bsp"
+)
+
+var tilingLayouts = []tilingLayout{layoutMasterStack, layoutBSP}
+
+func (l tilingLayout) valid() bool {
+	for _, v := range tilingLayouts {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
+
+// next returns the layout after l, wrapping around.
+func (l tilingLayout) next() tilingLayout {
+	for i, v := range tilingLayouts {
+		if l == v {
+			return tilingLayouts[(i+1)%len(tilingLayouts)]
+		}
+	}
+	return tilingLayouts[0]
+}
+
+// clampMasterPercent keeps the master share within 10-90% so neither side
+// of the split can disappear.
+func clampMasterPercent(p int32) int32 {
+	return max(10, min(90, p))
+}
+
+// tileLayout arranges n windows in work. masterPercent is the share of the
+// first split given to the first window.
+func tileLayout(layout tilingLayout, work w32.RECT, n int, masterPercent int32) []w32.RECT {
+	switch layout {
+	case layoutBSP:
+		return bspLayout(work, n, masterPercent)
+	default:
+		return masterStackLayout(work, n, masterPercent)
+	}
+}
+
+func masterStackLayout(work w32.RECT, n int, masterPercent int32) []w32.RECT {
+	if n <= 0 {
+		return nil
+	}
+	if n == 1 {
+		return []w32.RECT{work}
+	}
+	split := cut(work.Left, work.Width(), masterPercent, 100)
+	rects := []w32.RECT{{Left: work.Left, Top: work.Top, Right: split, Bottom: work.Bottom}}
+	bounds := splitSpan(work.Top, work.Height(), n-1)
+	for i := 0; i < n-1; i++ {
+		rects = append(rects, w32.RECT{Left: split, Top: bounds[i], Right: work.Right, Bottom: bounds[i+1]})
+	}
+	return rects
+}
+
+func bspLayout(work w32.RECT, n int, masterPercent int32) []w32.RECT {
+	var rects []w32.RECT
+	area := work
+	for i := 0; i < n; i++ {
+		if i == n-1 {
+			rects = append(rects, area)
+			break
+		}
+		percent := int32(50)
+		if i == 0 {
+			percent = masterPercent
+		}
+		tile := area
+		if area.Width() >= area.Height() {
+			tile.Right = cut(area.Left, area.Width(), percent, 100)
+			area.Left = tile.Right
+		} else {
+			tile.Bottom = cut(area.Top, area.Height(), percent, 100)
+			area.Top = tile.Bottom
+		}
+		rects = append(rects, tile)
+	}
+	return rects
+}
+
+// reconcileOrder brings a tiling order up to date with the windows present
+// now: windows that are gone are dropped, known windows keep their place
+// and new ones are added at the end in the order given.
+func reconcileOrder(order, present []w32.HWND) []w32.HWND {
+	isPresent := make(map[w32.HWND]bool, len(present))
+	for _, hwnd := range present {
+		isPresent[hwnd] = true
+	}
+	var result []w32.HWND
+	known := make(map[w32.HWND]bool, len(order))
+	for _, hwnd := range order {
+		if isPresent[hwnd] && !known[hwnd] {
+			result = append(result, hwnd)
+			known[hwnd] = true
+		}
+	}
+	for _, hwnd := range present {
+		if !known[hwnd] {
+			result = append(result, hwnd)
+			known[hwnd] = true
+		}
+	}
+	return result
+}
+
+func indexOfWindow(order []w32.HWND, hwnd w32.HWND) int {
+	for i, h := range order {
+		if h == hwnd {
+			return i
+		}
+	}
+	return -1
+}
+
+// promoteInOrder makes hwnd the master window. Promoting the master swaps
+// it with the next window, so the key toggles between the top two.
+func promoteInOrder(order []w32.HWND, hwnd w32.HWND) []w32.HWND {
+	i := indexOfWindow(order, hwnd)
+	if i < 0 || len(order) < 2 {
+		return order
+	}
+	result := append([]w32.HWND(nil), order...)
+	if i == 0 {
+		result[0], result[1] = result[1], result[0]
+		return result
+	}
+	copy(result[1:i+1], order[:i])
+	result[0] = hwnd
+	return result
+}
+
+// swapInOrder swaps hwnd with the window delta places away, wrapping
+// around the ends.
+func swapInOrder(order []w32.HWND, hwnd w32.HWND, delta int) []w32.HWND {
+	i := indexOfWindow(order, hwnd)
+	if i < 0 || len(order) < 2 {
+		return order
+	}
+	j := ((i+delta)%len(order) + len(order)) % len(order)
+	result := append([]w32.HWND(nil), order...)
+	result[i], result[j] = result[j], result[i]
+	return result
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 Phoeagon
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonutz/w32/v2"
+)
+
+func TestSplitSpan(t *testing.T) {
+	cases := []struct {
+		start, length int32
+		n             int
+		want          []int32
+	}{
+		{0, 100, 1, []int32{0, 100}},
+		{0, 100, 2, []int32{0, 50, 100}},
+		{0, 100, 3, []int32{0, 33, 66, 100}},
+		{10, 7, 4, []int32{10, 11, 13, 15, 17}},
+	}
+	for _, c := range cases {
+		if got := splitSpan(c.start, c.length, c.n); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("splitSpan(%d, %d, %d) = %v, want %v", c.start, c.length, c.n, got, c.want)
+		}
+	}
+}
+
+func TestMasterStackLayout(t *testing.T) {
+	work := rect(0, 0, 1000, 900)
+	if got := masterStackLayout(work, 0, 50); got != nil {
+		t.Errorf("no windows = %v, want nil", got)
+	}
+	if got, want := masterStackLayout(work, 1, 50), []w32.RECT{work}; !reflect.DeepEqual(got, want) {
+		t.Errorf("one window = %v, want %v", got, want)
+	}
+	got := masterStackLayout(work, 4, 60)
+	want := []w32.RECT{
+		rect(0, 0, 600, 900),
+		rect(600, 0, 1000, 300),
+		rect(600, 300, 1000, 600),
+		rect(600, 600, 1000, 900),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("four windows = %v, want %v", got, want)
+	}
+}
+
+func TestBSPLayout(t *testing.T) {
+	work := rect(0, 0, 1600, 900)
+	got := bspLayout(work, 4, 50)
+	want := []w32.RECT{
+		rect(0, 0, 800, 900),    // wide: split left/right
+		rect(800, 0, 1600, 450), // tall: split top/bottom
+		rect(800, 450, 1200, 900),
+		rect(1200, 450, 1600, 900),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("four windows = %v, want %v", got, want)
+	}
+	if got := bspLayout(work, 2, 70); got[0] != rect(0, 0, 1120, 900) || got[1] != rect(1120, 0, 1600, 900) {
+		t.Errorf("master percent not applied to the first split: %v", got)
+	}
+}
+
+// Every layout must cover the work area exactly: no gaps, no overlaps,
+// nothing outside, and no empty tiles.
+func TestTileLayoutCoversWorkArea(t *testing.T) {
+	works := []w32.RECT{
+		rect(0, 0, 1920, 1040),
+		rect(-1280, 200, 0, 1224), // monitor left of the primary one
+		rect(0, 0, 1001, 777),     // odd sizes
+		rect(0, 0, 800, 1280),     // portrait
+	}
+	for _, layout := range tilingLayouts {
+		for _, work := range works {
+			for _, pct := range []int32{25, 50, 55, 75} {
+				for n := 1; n <= 8; n++ {
+					rects := tileLayout(layout, work, n, pct)
+					if len(rects) != n {
+						t.Fatalf("%s %v n=%d: got %d rects", layout, work, n, len(rects))
+					}
+					var area int64
+					for i, r := range rects {
+						if r.Width() <= 0 || r.Height() <= 0 {
+							t.Errorf("%s %v n=%d pct=%d: tile %d is empty: %v", layout, work, n, pct, i, r)
+						}
+						if r.Left < work.Left || r.Top < work.Top || r.Right > work.Right || r.Bottom > work.Bottom {
+							t.Errorf("%s %v n=%d pct=%d: tile %d outside work area: %v", layout, work, n, pct, i, r)
+						}
+						area += int64(r.Width()) * int64(r.Height())
+						for j := 0; j < i; j++ {
+							if overlaps(r, rects[j]) {
+								t.Errorf("%s %v n=%d pct=%d: tiles %d and %d overlap: %v %v", layout, work, n, pct, j, i, rects[j], r)
+							}
+						}
+					}
+					if want := int64(work.Width()) * int64(work.Height()); area != want {
+						t.Errorf("%s %v n=%d pct=%d: tiles cover %d px, want %d", layout, work, n, pct, area, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func overlaps(a, b w32.RECT) bool {
+	return a.Left < b.Right && b.Left < a.Right && a.Top < b.Bottom && b.Top < a.Bottom
+}
+
+func TestTilingLayoutNext(t *testing.T) {
+	if got := layoutMasterStack.next(); got != layoutBSP {
+		t.Errorf("master_stack.next() = %s, want bsp", got)
+	}
+	if got := layoutBSP.next(); got != layoutMasterStack {
+		t.Errorf("bsp.next() = %s, want master_stack", got)
+	}
+	if tilingLayout("spiral").valid() {
+		t.Error("unknown layout reported valid")
+	}
+}
+
+func TestReconcileOrder(t *testing.T) {
+	order := []w32.HWND{1, 2, 3, 4}
+	got := reconcileOrder(order, []w32.HWND{5, 4, 2, 1})
+	if want := []w32.HWND{1, 2, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reconcileOrder = %v, want %v", got, want)
+	}
+	if got := reconcileOrder(nil, []w32.HWND{3, 1}); !reflect.DeepEqual(got, []w32.HWND{3, 1}) {
+		t.Errorf("reconcileOrder from empty = %v, want [3 1]", got)
+	}
+	if got := reconcileOrder(order, nil); len(got) != 0 {
+		t.Errorf("reconcileOrder with nothing present = %v, want empty", got)
+	}
+	if !reflect.DeepEqual(order, []w32.HWND{1, 2, 3, 4}) {
+		t.Errorf("reconcileOrder modified its input: %v", order)
+	}
+}
+
+func TestPromoteInOrder(t *testing.T) {
+	order := []w32.HWND{1, 2, 3, 4}
+	cases := []struct {
+		hwnd w32.HWND
+		want []w32.HWND
+	}{
+		{3, []w32.HWND{3, 1, 2, 4}},
+		{4, []w32.HWND{4, 1, 2, 3}},
+		{2, []w32.HWND{2, 1, 3, 4}},
+		{1, []w32.HWND{2, 1, 3, 4}}, // master swaps with the next one
+		{9, []w32.HWND{1, 2, 3, 4}}, // not tiled
+	}
+	for _, c := range cases {
+		if got := promoteInOrder(order, c.hwnd); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("promoteInOrder(%d) = %v, want %v", c.hwnd, got, c.want)
+		}
+	}
+	if !reflect.DeepEqual(order, []w32.HWND{1, 2, 3, 4}) {
+		t.Errorf("promoteInOrder modified its input: %v", order)
+	}
+}
+
+func TestSwapInOrder(t *testing.T) {
+	order := []w32.HWND{1, 2, 3}
+	cases := []struct {
+		hwnd  w32.HWND
+		delta int
+		want  []w32.HWND
+	}{
+		{1, 1, []w32.HWND{2, 1, 3}},
+		{3, 1, []w32.HWND{3, 2, 1}}, // wraps to the front
+		{1, -1, []w32.HWND{3, 2, 1}},
+		{2, -1, []w32.HWND{2, 1, 3}},
+	}
+	for _, c := range cases {
+		if got := swapInOrder(order, c.hwnd, c.delta); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("swapInOrder(%d, %d) = %v, want %v", c.hwnd, c.delta, got, c.want)
+		}
+	}
+}
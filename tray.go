@@ -19,9 +19,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sync"
 
 	"github.com/getlantern/systray"
 	"github.com/gonutz/w32/v2"
+	"github.com/phoeagon/RectangleWinPlus/w32ex"
 )
 
 //go:embed assets/tray_icon.ico
@@ -131,15 +133,11 @@ func onReady() {
 			openSettingsUI()
 		}
 	}()
-	showLoadedConfig := systray.AddMenuItem("Show loaded config", "")
+	showHotkeys := systray.AddMenuItem("Show hotkeys", "")
 	go func() {
-		<-showLoadedConfig.ClickedCh
-		msg := "Here are the availble hotkeys:\n\n"
-		for _, hotkey := range hks {
-			msg += fmt.Sprintf("%s: %v\n", hotkey.bindFeature, hotkey.Describe())
+		for range showHotkeys.ClickedCh {
+			cheatSheet()
 		}
-		msg += "\nTo change this config, update the config file at %HOME/.config/RectangleWinPlus/config.json"
-		showMessageBox(msg)
 	}()
 	resetToDefault := systray.AddMenuItem("Reset to default", "")
 	go func() {
@@ -163,12 +161,23 @@ func onReady() {
 	menuHeader := systray.AddMenuItem("Features", "")
 	menuHeader.Disable()
 
-	for _, f := range features {
-		title := f.DisplayName
-		if f.HotkeyDesc != "" {
-			title += fmt.Sprintf(" (%s)", f.HotkeyDesc)
+	submenus := map[string]*systray.MenuItem{}
+	featureItems := make([]*systray.MenuItem, len(features))
+	failed := failedHotKeys.list()
+	for i, f := range features {
+		title := featureMenuTitle(f, failed)
+		var mItem *systray.MenuItem
+		if f.Submenu == "" {
+			mItem = systray.AddMenuItem(title, "")
+		} else {
+			parent, ok := submenus[f.Submenu]
+			if !ok {
+				parent = systray.AddMenuItem(f.Submenu, "")
+				submenus[f.Submenu] = parent
+			}
+			mItem = parent.AddSubMenuItem(title, "")
 		}
-		mItem := systray.AddMenuItem(title, "")
+		featureItems[i] = mItem
 		// Capture variable for closure
 		callback := f.Callback
 		go func() {
@@ -177,6 +186,10 @@ func onReady() {
 			}
 		}()
 	}
+	addRetryHotkeysMenu(featureItems)
+	addHiddenWindowsMenu()
+	addPinnedMenu()
+	addEffectsMenu()
 	systray.AddSeparator()
 	mRestart := systray.AddMenuItem("Restart to apply config", "")
 	go func() {
@@ -197,4 +210,154 @@ func onReady() {
 
 func onExit() {
 	fmt.Println("onExit invoked")
+	// don't leave windows hidden, shrunk or unclickable with no way to
+	// bring them back; pins survive a restart
+	unhideAllWindows()
+	leaveAllPictureInPicture()
+	releaseEffects()
+	// and stop seeing every click and keystroke
+	uninstallMouseHook()
+	uninstallKeyHook()
+}
+
+// addHiddenWindowsMenu adds the submenu listing the windows hidden by
+// hideToTray. It only shows while there are any.
+func addHiddenWindowsMenu() {
+	update := addWindowListMenu("Hidden Windows", "Show All", unhideAllWindows, unhideWindow)
+	onHiddenWindowsChanged = func() {
+		var entries []windowListEntry
+		for _, w := range hiddenWindows.list() {
+			entries = append(entries, windowListEntry{w.hwnd, w.title, ""})
+		}
+		update(entries)
+	}
+	onHiddenWindowsChanged()
+}
+
+// addEffectsMenu adds the submenu listing the windows made always on top,
+// transparent or click-through. Clicking one puts it back the way it was.
+func addEffectsMenu() {
+	update := addWindowListMenu("Window Effects", "Reset All", resetAllEffects, resetEffects)
+	changed := func() {
+		var entries []windowListEntry
+		for _, w := range effectWindows() {
+			entries = append(entries, windowListEntry{w.hwnd, w.effect.title, w.effect.describe()})
+		}
+		update(entries)
+	}
+	watchEffects(changed)
+	changed()
+}
+
+// addRetryHotkeysMenu adds the item retrying the hotkeys that failed to
+// register, shown while there are any, and keeps the marks on the
+// featureItems of their features up to date.
+func addRetryHotkeysMenu(featureItems []*systray.MenuItem) {
+	mRetry := systray.AddMenuItem("Retry Failed Hotkeys", "")
+	go func() {
+		for range mRetry.ClickedCh {
+			// hotkeys belong to the thread that registers them
+			w32ex.PostThreadMessage(msgLoopThread, msgRetryHotKeys, 0, 0)
+		}
+	}()
+	var mu sync.Mutex
+	changed := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		failed := failedHotKeys.list()
+		for i, f := range features {
+			featureItems[i].SetTitle(featureMenuTitle(f, failed))
+		}
+		if len(failed) == 0 {
+			mRetry.Hide()
+			return
+		}
+		mRetry.SetTitle(fmt.Sprintf("Retry Failed Hotkeys (%d)", len(failed)))
+		mRetry.Show()
+	}
+	watchHotkeys(changed)
+	changed()
+}
+
+// addPinnedMenu adds the submenu listing the windows kept always on top,
+// with an item to unpin each.
+func addPinnedMenu() {
+	update := addWindowListMenu("Pinned Windows", "Unpin All", unpinAll, unpin)
+	changed := func() {
+		var entries []windowListEntry
+		for _, w := range pinnedWindows() {
+			entries = append(entries, windowListEntry{w.hwnd, w.effect.title, ""})
+		}
+		update(entries)
+	}
+	watchEffects(changed)
+	changed()
+}
+
+type windowListEntry struct {
+	hwnd  w32.HWND
+	title string
+	// shown in parentheses after the title, if set
+	detail string
+}
+
+// addWindowListMenu adds a submenu with an item acting on all windows,
+// then one item per window. It returns the function to update the list;
+// the submenu is hidden while the list is empty.
+func addWindowListMenu(title, allTitle string, onAll func(), onClick func(w32.HWND)) func([]windowListEntry) {
+	mParent := systray.AddMenuItem(title, "")
+	mAll := mParent.AddSubMenuItem(allTitle, "")
+	go func() {
+		for range mAll.ClickedCh {
+			onAll()
+		}
+	}()
+
+	// Menu items can't be removed, so there is one per window, reused as
+	// windows come and go.
+	var mu sync.Mutex
+	var items []*systray.MenuItem
+	var itemWindows []w32.HWND
+	return func(list []windowListEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		for len(items) < len(list) {
+			i := len(items)
+			item := mParent.AddSubMenuItem("", "")
+			items = append(items, item)
+			itemWindows = append(itemWindows, 0)
+			go func() {
+				for range item.ClickedCh {
+					mu.Lock()
+					hwnd := itemWindows[i]
+					mu.Unlock()
+					if hwnd != 0 {
+						onClick(hwnd)
+					}
+				}
+			}()
+		}
+		for i, item := range items {
+			if i < len(list) {
+				itemWindows[i] = list[i].hwnd
+				title := list[i].title
+				if title == "" {
+					title = "(untitled window)"
+				}
+				if list[i].detail != "" {
+					title += " (" + list[i].detail + ")"
+				}
+				item.SetTitle(title)
+				item.Show()
+			} else {
+				itemWindows[i] = 0
+				item.Hide()
+			}
+		}
+		if len(list) == 0 {
+			mParent.Hide()
+		} else {
+			mParent.Show()
+		}
+	}
 }
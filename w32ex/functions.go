@@ -31,10 +31,37 @@ const (
 )
 
 var (
-	user32 = syscall.NewLazyDLL("user32.dll")
-	shcore = syscall.NewLazyDLL("shcore.dll")
+	user32   = syscall.NewLazyDLL("user32.dll")
+	shcore   = syscall.NewLazyDLL("shcore.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+	dwmapi   = syscall.NewLazyDLL("dwmapi.dll")
 )
 
+const (
+	EVENT_SYSTEM_MOVESIZEEND    = 0x000B
+	EVENT_SYSTEM_MINIMIZESTART  = 0x0016
+	EVENT_SYSTEM_MINIMIZEEND    = 0x0017
+	EVENT_OBJECT_DESTROY        = 0x8001
+	EVENT_OBJECT_SHOW           = 0x8002
+	EVENT_OBJECT_HIDE           = 0x8003
+	EVENT_OBJECT_LOCATIONCHANGE = 0x800B
+	EVENT_OBJECT_CLOAKED        = 0x8017
+	EVENT_OBJECT_UNCLOAKED      = 0x8018
+	WINEVENT_OUTOFCONTEXT       = 0x0000
+	WINEVENT_SKIPOWNPROCESS     = 0x0002
+	OBJID_WINDOW                = 0
+	DWMWA_CLOAKED               = 14
+)
+
+// https://learn.microsoft.com/en-us/windows/win32/api/winuser/ns-winuser-msllhookstruct
+type MSLLHOOKSTRUCT struct {
+	Pt          w32.POINT
+	MouseData   uint32
+	Flags       uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
 func RegisterHotKey(hwnd w32.HWND, id, mod, vk int) bool {
 	r1, _, _ := user32.NewProc("RegisterHotKey").Call(uintptr(hwnd), uintptr(id), uintptr(mod), uintptr(vk))
 	return r1 != 0
@@ -45,6 +72,83 @@ func UnregisterHotKey(hwnd w32.HWND, id int) bool {
 	return r1 != 0
 }
 
+func GetCurrentThreadId() uint32 {
+	r1, _, _ := kernel32.NewProc("GetCurrentThreadId").Call()
+	return uint32(r1)
+}
+
+func PostThreadMessage(threadID uint32, msg uint32, wParam, lParam uintptr) bool {
+	r1, _, _ := user32.NewProc("PostThreadMessageW").Call(uintptr(threadID), uintptr(msg), wParam, lParam)
+	return r1 != 0
+}
+
+func KillTimer(hwnd w32.HWND, id uintptr) bool {
+	r1, _, _ := user32.NewProc("KillTimer").Call(uintptr(hwnd), id)
+	return r1 != 0
+}
+
+func WindowFromPoint(x, y int32) w32.HWND {
+	var r1 uintptr
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		// POINT is passed by value, packed into a single register
+		r1, _, _ = user32.NewProc("WindowFromPoint").Call(uintptr(uint32(x)) | uintptr(uint32(y))<<32)
+	} else {
+		r1, _, _ = user32.NewProc("WindowFromPoint").Call(uintptr(x), uintptr(y))
+	}
+	return w32.HWND(r1)
+}
+
+func IsZoomed(hwnd w32.HWND) bool {
+	r1, _, _ := user32.NewProc("IsZoomed").Call(uintptr(hwnd))
+	return r1 != 0
+}
+
+func IsIconic(hwnd w32.HWND) bool {
+	r1, _, _ := user32.NewProc("IsIconic").Call(uintptr(hwnd))
+	return r1 != 0
+}
+
+// IsCloaked reports whether DWM hides the window, e.g. because it lives on
+// another virtual desktop or is a suspended UWP app. Such windows still
+// report as visible.
+func IsCloaked(hwnd w32.HWND) bool {
+	var cloaked uint32
+	r1, _, _ := dwmapi.NewProc("DwmGetWindowAttribute").Call(uintptr(hwnd), DWMWA_CLOAKED,
+		uintptr(unsafe.Pointer(&cloaked)), unsafe.Sizeof(cloaked))
+	return r1 == 0 /* S_OK */ && cloaked != 0
+}
+
+// SetWinEventHook installs an out-of-context hook; callback must come from
+// syscall.NewCallback.
+func SetWinEventHook(eventMin, eventMax uint32, callback uintptr, flags uint32) uintptr {
+	r1, _, _ := user32.NewProc("SetWinEventHook").Call(uintptr(eventMin), uintptr(eventMax), 0,
+		callback, 0, 0, uintptr(flags))
+	return r1
+}
+
+func UnhookWinEvent(hook uintptr) bool {
+	r1, _, _ := user32.NewProc("UnhookWinEvent").Call(hook)
+	return r1 != 0
+}
+
+func BeginDeferWindowPos(n int) uintptr {
+	r1, _, _ := user32.NewProc("BeginDeferWindowPos").Call(uintptr(n))
+	return r1
+}
+
+// DeferWindowPos returns the handle to pass to the next call, which may
+// differ from hdwp, or 0 on failure.
+func DeferWindowPos(hdwp uintptr, hwnd, insertAfter w32.HWND, x, y, cx, cy int, flags uint) uintptr {
+	r1, _, _ := user32.NewProc("DeferWindowPos").Call(hdwp, uintptr(hwnd), uintptr(insertAfter),
+		uintptr(x), uintptr(y), uintptr(cx), uintptr(cy), uintptr(flags))
+	return r1
+}
+
+func EndDeferWindowPos(hdwp uintptr) bool {
+	r1, _, _ := user32.NewProc("EndDeferWindowPos").Call(hdwp)
+	return r1 != 0
+}
+
 func GetDpiForWindow(hwnd w32.HWND) int32 {
 	r1, _, _ := user32.NewProc("GetDpiForWindow").Call(uintptr(hwnd))
 	return int32(r1)
@@ -95,3 +199,11 @@ func SetProcessDPIAware() bool {
 	r1, _, _ := user32.NewProc("SetProcessDPIAware").Call()
 	return r1 != 0
 }
+
+// GetLayeredWindowAttributes returns the color key, opacity and LWA_ flags
+// of a layered window.
+func GetLayeredWindowAttributes(hwnd w32.HWND) (key w32.COLORREF, alpha byte, flags uint32, ok bool) {
+	r1, _, _ := user32.NewProc("GetLayeredWindowAttributes").Call(uintptr(hwnd),
+		uintptr(unsafe.Pointer(&key)), uintptr(unsafe.Pointer(&alpha)), uintptr(unsafe.Pointer(&flags)))
+	return key, alpha, flags, r1 != 0
+}
@@ -16,14 +16,13 @@ func showMessageBox(text string) {
 type resizeFunc func(disp, cur w32.RECT) w32.RECT
 
 func center(disp, cur w32.RECT) w32.RECT {
-	// TODO find a way to round up divisions consistently as it causes multiple runs to shift by 1px
-	w := (disp.Width() - cur.Width()) / 2
-	h := (disp.Height() - cur.Height()) / 2
+	left := centerSpan(disp.Left, disp.Width(), cur.Width())
+	top := centerSpan(disp.Top, disp.Height(), cur.Height())
 	return w32.RECT{
-		Left:   disp.Left + w,
-		Right:  disp.Left + w + cur.Width(),
-		Top:    disp.Top + h,
-		Bottom: disp.Top + h + cur.Height()}
+		Left:   left,
+		Right:  left + cur.Width(),
+		Top:    top,
+		Bottom: top + cur.Height()}
 }
 
 func resize(hwnd w32.HWND, f resizeFunc) (bool, error) {
@@ -34,7 +33,6 @@ func resizeAcrossMonitor(hwnd w32.HWND, f resizeFunc, monitorIndexDiff int) (boo
 		fmt.Printf("warn: non-zonable window: %s\n", w32.GetWindowText(hwnd))
 		return false, nil
 	}
-	rect := w32.GetWindowRect(hwnd)
 	mon := w32.MonitorFromWindow(hwnd, w32.MONITOR_DEFAULTTONEAREST)
 	if monitorIndexDiff != 0 {
 		monitorCount := w32.GetSystemMetrics(80 /*SM_CMONITORS*/)
@@ -75,6 +73,25 @@ func resizeAcrossMonitor(hwnd w32.HWND, f resizeFunc, monitorIndexDiff int) (boo
 			mon = originalWindowMonitor
 		}
 	}
+	return resizeOnMonitor(hwnd, f, mon)
+}
+
+// resizeToDisplay moves the window to the display at the given 0-based index.
+func resizeToDisplay(hwnd w32.HWND, f resizeFunc, index int) (bool, error) {
+	if !isZonableWindow(hwnd) {
+		fmt.Printf("warn: non-zonable window: %s\n", w32.GetWindowText(hwnd))
+		return false, nil
+	}
+	mon, ok := monitorAt(index)
+	if !ok {
+		return false, fmt.Errorf("no display #%d", index+1)
+	}
+	return resizeOnMonitor(hwnd, f, mon)
+}
+
+// resizeOnMonitor applies f to a zonable window using the work area of mon.
+func resizeOnMonitor(hwnd w32.HWND, f resizeFunc, mon w32.HMONITOR) (bool, error) {
+	rect := w32.GetWindowRect(hwnd)
 	var monInfo w32.MONITORINFO
 	if !w32.GetMonitorInfo(mon, &monInfo) {
 		return false, fmt.Errorf("failed to GetMonitorInfo:%d", w32.GetLastError())
@@ -143,23 +160,6 @@ func getTargetWindow() w32.HWND {
 	return 0
 }
 
-func toggleAlwaysOnTop(hwnd w32.HWND) error {
-	if !isZonableWindow(hwnd) {
-		return errors.New("foreground window is not zonable")
-	}
-
-	if w32.GetWindowLong(hwnd, w32.GWL_EXSTYLE)&w32.WS_EX_TOPMOST != 0 {
-		if !w32.SetWindowPos(hwnd, w32.HWND_NOTOPMOST, 0, 0, 0, 0, w32.SWP_NOMOVE|w32.SWP_NOSIZE) {
-			return fmt.Errorf("failed to SetWindowPos(HWND_NOTOPMOST): %v", w32.GetLastError())
-		}
-	} else {
-		if !w32.SetWindowPos(hwnd, w32.HWND_TOPMOST, 0, 0, 0, 0, w32.SWP_NOMOVE|w32.SWP_NOSIZE) {
-			return fmt.Errorf("failed to SetWindowPos(HWND_TOPMOST) :%v", w32.GetLastError())
-		}
-	}
-	return nil
-}
-
 func resizeForDpi(src w32.RECT, from, to int32) w32.RECT {
 	return w32.RECT{
 		Left:   src.Left * to / from,
@@ -172,3 +172,135 @@ func resizeForDpi(src w32.RECT, from, to int32) w32.RECT {
 func sameRect(a, b *w32.RECT) bool {
 	return a != nil && b != nil && reflect.DeepEqual(*a, *b)
 }
+
+// placeWindow moves hwnd so that its visible frame is frame. Unlike
+// resizeOnMonitor it never activates the window, restoring a maximized or
+// minimized one with SW_SHOWNOACTIVATE.
+func placeWindow(hwnd w32.HWND, frame w32.RECT) error {
+	if w32ex.IsZoomed(hwnd) || w32ex.IsIconic(hwnd) {
+		w32.ShowWindow(hwnd, w32.SW_SHOWNOACTIVATE)
+	}
+	r, ok := windowRectForFrame(hwnd, frame)
+	if !ok {
+		return fmt.Errorf("failed to DwmGetWindowAttributeEXTENDED_FRAME_BOUNDS:%d", w32.GetLastError())
+	}
+	if !w32.SetWindowPos(hwnd, 0, int(r.Left), int(r.Top), int(r.Width()), int(r.Height()), w32.SWP_NOZORDER|w32.SWP_NOACTIVATE) {
+		return fmt.Errorf("failed to SetWindowPos:%d", w32.GetLastError())
+	}
+	return nil
+}
+
+// windowFrame returns the visible frame of hwnd, without the invisible
+// borders.
+func windowFrame(hwnd w32.HWND) (w32.RECT, bool) {
+	ok, frame := w32.DwmGetWindowAttributeEXTENDED_FRAME_BOUNDS(hwnd)
+	return frame, ok
+}
+
+// neighborWindows returns the other windows on the monitor of hwnd that
+// can swap places with it, and their frames.
+func neighborWindows(hwnd w32.HWND) ([]w32.HWND, []w32.RECT) {
+	mon := w32.MonitorFromWindow(hwnd, w32.MONITOR_DEFAULTTONEAREST)
+	var windows []w32.HWND
+	var frames []w32.RECT
+	for _, w := range tilableWindows(mon, zonableWindows()) {
+		if w == hwnd {
+			continue
+		}
+		if frame, ok := windowFrame(w); ok {
+			windows = append(windows, w)
+			frames = append(frames, frame)
+		}
+	}
+	return windows, frames
+}
+
+// swapWindows exchanges the places of two windows on the same monitor.
+func swapWindows(a, b w32.HWND) error {
+	mon := w32.MonitorFromWindow(a, w32.MONITOR_DEFAULTTONEAREST)
+	if swapTiledWindows(mon, a, b) {
+		return nil
+	}
+	fa, ok := windowFrame(a)
+	if !ok {
+		return fmt.Errorf("failed to DwmGetWindowAttributeEXTENDED_FRAME_BOUNDS:%d", w32.GetLastError())
+	}
+	fb, ok := windowFrame(b)
+	if !ok {
+		return fmt.Errorf("failed to DwmGetWindowAttributeEXTENDED_FRAME_BOUNDS:%d", w32.GetLastError())
+	}
+	place := func(r w32.RECT) resizeFunc {
+		return func(_, _ w32.RECT) w32.RECT { return r }
+	}
+	// b first, so that a, which resizeOnMonitor also activates, stays in front
+	if _, err := resizeOnMonitor(b, place(fa), mon); err != nil {
+		return err
+	}
+	_, err := resizeOnMonitor(a, place(fb), mon)
+	return err
+}
+
+func swapWithNeighbor(d direction) error {
+	hwnd := getTargetWindow()
+	frame, ok := windowFrame(hwnd)
+	if !isZonableWindow(hwnd) || !ok {
+		return errors.New("foreground window is not zonable")
+	}
+	windows, frames := neighborWindows(hwnd)
+	i := nearestInDirection(frame, frames, d)
+	if i < 0 {
+		fmt.Println("no window in that direction")
+		return nil
+	}
+	return swapWindows(hwnd, windows[i])
+}
+
+func swapWithNext() error {
+	hwnd := getTargetWindow()
+	frame, ok := windowFrame(hwnd)
+	if !isZonableWindow(hwnd) || !ok {
+		return errors.New("foreground window is not zonable")
+	}
+	windows, frames := neighborWindows(hwnd)
+	i := nextInReadingOrder(frame, frames)
+	if i < 0 {
+		fmt.Println("no other window on this monitor")
+		return nil
+	}
+	return swapWindows(hwnd, windows[i])
+}
+
+// focusInDirection activates the nearest visible window in direction d,
+// on any monitor. Windows completely covered by others are skipped.
+func focusInDirection(d direction) error {
+	hwnd := getTargetWindow()
+	from, ok := windowFrame(hwnd)
+	if !isZonableWindow(hwnd) || !ok {
+		return errors.New("foreground window is not zonable")
+	}
+	var windows []w32.HWND
+	var frames []w32.RECT
+	for _, w := range zonableWindows() {
+		if frame, ok := windowFrame(w); ok {
+			windows = append(windows, w)
+			frames = append(frames, frame)
+		}
+	}
+	var candidates []w32.HWND
+	var candidateFrames []w32.RECT
+	for _, i := range uncoveredWindows(frames) {
+		if windows[i] != hwnd {
+			candidates = append(candidates, windows[i])
+			candidateFrames = append(candidateFrames, frames[i])
+		}
+	}
+	i := nearestInDirection(from, candidateFrames, d)
+	if i < 0 {
+		fmt.Println("no window in that direction")
+		return nil
+	}
+	if !w32.SetForegroundWindow(candidates[i]) {
+		return fmt.Errorf("failed to SetForegroundWindow:%d", w32.GetLastError())
+	}
+	return nil
+}
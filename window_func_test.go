@@ -17,6 +17,23 @@ func TestCenter(t *testing.T) {
 	}
 }
 
+// Centering must not drift when repeated or change the window's size, also
+// on a monitor left of the primary one and for windows larger than it.
+func TestCenterStable(t *testing.T) {
+	for w := int32(1); w <= 40; w++ {
+		for cw := int32(1); cw <= 50; cw++ {
+			disp := rect(-w, 7, 0, 7+w+3)
+			once := center(disp, rect(0, 0, cw, cw))
+			if twice := center(disp, once); twice != once {
+				t.Fatalf("center %dx%d in %v: %v then %v", cw, cw, disp, once, twice)
+			}
+			if once.Width() != cw || once.Height() != cw {
+				t.Fatalf("center %dx%d in %v changed the size: %v", cw, cw, disp, once)
+			}
+		}
+	}
+}
+
 func TestResizeForDpi(t *testing.T) {
 	src := w32.RECT{Left: 10, Top: 20, Right: 110, Bottom: 220}
 	// Scale from DPI 96 to 192 (factor 2)